@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
@@ -11,6 +12,35 @@ import (
 	"github.com/wenealves10/gobank/token"
 )
 
+type accountResponse struct {
+	ID             int64      `json:"id"`
+	Owner          string     `json:"owner"`
+	Balance        money      `json:"balance"`
+	Currency       string     `json:"currency"`
+	CreatedAt      time.Time  `json:"created_at"`
+	OverdraftLimit money      `json:"overdraft_limit"`
+	Status         string     `json:"status"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+}
+
+func newAccountResponse(account db.Account, decimal bool) accountResponse {
+	rsp := accountResponse{
+		ID:             account.ID,
+		Owner:          account.Owner,
+		Balance:        newMoney(account.Balance, account.Currency, decimal),
+		Currency:       account.Currency,
+		CreatedAt:      account.CreatedAt,
+		OverdraftLimit: newMoney(account.OverdraftLimit, account.Currency, decimal),
+		Status:         account.Status,
+	}
+
+	if account.ClosedAt.Valid {
+		rsp.ClosedAt = &account.ClosedAt.Time
+	}
+
+	return rsp
+}
+
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
 }
@@ -42,7 +72,7 @@ func (s *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(account, decimalAmounts(ctx)))
 }
 
 type getAccountRequest struct {
@@ -56,30 +86,18 @@ func (s *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := s.store.GetAccount(ctx, req.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
-		err := errors.New("account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	account, valid := s.authorizedAccount(ctx, req.ID, permReadAccount)
+	if !valid {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(account, decimalAmounts(ctx)))
 }
 
 type listAccountRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	Owner    string `form:"owner" binding:"omitempty,alphanum"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
 }
 
 func (s *Server) listAccount(ctx *gin.Context) {
@@ -90,8 +108,18 @@ func (s *Server) listAccount(ctx *gin.Context) {
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	owner := authPayload.Username
+	if req.Owner != "" && req.Owner != owner {
+		if !roleAllows(authPayload, permListAnyAccounts) {
+			err := errors.New("cannot list accounts of another user")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		owner = req.Owner
+	}
+
 	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
+		Owner:  owner,
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
 	}
@@ -102,5 +130,92 @@ func (s *Server) listAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	rsp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp[i] = newAccountResponse(account, decimalAmounts(ctx))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type accountStatusRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) freezeAccount(ctx *gin.Context) {
+	s.setAccountStatus(ctx, db.AccountStatusActive, db.AccountStatusFrozen)
+}
+
+func (s *Server) unfreezeAccount(ctx *gin.Context) {
+	s.setAccountStatus(ctx, db.AccountStatusFrozen, db.AccountStatusActive)
+}
+
+// closeAccount closes an empty account. It stays readable, with its
+// entries and transfers, but can no longer send or receive money.
+func (s *Server) closeAccount(ctx *gin.Context) {
+	if s.ownedAccountStatusRequest(ctx) {
+		s.setAccountStatus(ctx, db.AccountStatusActive, db.AccountStatusClosed)
+	}
+}
+
+// reopenAccount reopens a closed account. It never lifts a freeze, which
+// only whoever may freeze accounts can do.
+func (s *Server) reopenAccount(ctx *gin.Context) {
+	if s.ownedAccountStatusRequest(ctx) {
+		s.setAccountStatus(ctx, db.AccountStatusClosed, db.AccountStatusActive)
+	}
+}
+
+// ownedAccountStatusRequest checks that the caller may close or reopen the
+// account in the URI, writing the error response when they may not.
+func (s *Server) ownedAccountStatusRequest(ctx *gin.Context) bool {
+	var req accountStatusRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+
+	_, valid := s.authorizedAccount(ctx, req.ID, permCloseAccount)
+	return valid
+}
+
+// setAccountStatus moves the account in the URI from status from to status.
+func (s *Server) setAccountStatus(ctx *gin.Context, from string, status string) {
+	var req accountStatusRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := s.store.UpdateAccountStatusTx(ctx, db.UpdateAccountStatusTxParams{
+		AccountID: req.ID,
+		From:      from,
+		Status:    status,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrInvalidStatusTransition) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrAccountNotEmpty) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrSystemAccount) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account, decimalAmounts(ctx)))
 }
@@ -37,7 +37,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "OK",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -53,7 +53,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "NotFound",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -68,7 +68,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "UnauthorizedUser",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "unauthorized_user", time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "unauthorized_user", utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -79,6 +79,22 @@ func TestGetAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name:      "BankerViewsAnyAccount",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
 		{
 			name:      "NoAuthorization",
 			accountID: account.ID,
@@ -97,7 +113,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "InternalError",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -112,7 +128,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "InvalidID",
 			accountID: 0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -168,7 +184,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": account.Currency,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				arg := db.CreateAccountParams{
@@ -208,7 +224,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": account.Currency,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -225,7 +241,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": account.Currency,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -242,7 +258,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": account.Currency,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -259,7 +275,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": "invalid",
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -312,6 +328,7 @@ func TestListAccountsAPI(t *testing.T) {
 	}
 
 	type Query struct {
+		owner    string
 		pageID   int
 		pageSize int
 	}
@@ -330,7 +347,7 @@ func TestListAccountsAPI(t *testing.T) {
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				arg := db.ListAccountsParams{
@@ -355,7 +372,7 @@ func TestListAccountsAPI(t *testing.T) {
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -366,6 +383,51 @@ func TestListAccountsAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "BankerListsAnotherOwner",
+			query: Query{
+				owner:    user.Username,
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.ListAccountsParams{
+					Owner:  user.Username,
+					Limit:  int32(n),
+					Offset: 0,
+				}
+
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, accounts)
+			},
+		},
+		{
+			name: "DepositorListsAnotherOwner",
+			query: Query{
+				owner:    user.Username,
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "unauthorized_user", utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "InvalidPageID",
 			query: Query{
@@ -373,7 +435,7 @@ func TestListAccountsAPI(t *testing.T) {
 				pageSize: n,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -391,7 +453,7 @@ func TestListAccountsAPI(t *testing.T) {
 				pageSize: 0,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
@@ -421,6 +483,9 @@ func TestListAccountsAPI(t *testing.T) {
 			require.NoError(t, err)
 
 			query := request.URL.Query()
+			if tc.query.owner != "" {
+				query.Add("owner", tc.query.owner)
+			}
 			query.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
 			query.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
 			request.URL.RawQuery = query.Encode()
@@ -432,12 +497,346 @@ func TestListAccountsAPI(t *testing.T) {
 	}
 }
 
+func TestFreezeAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		method        string
+		accountID     int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator)
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Freeze",
+			method:    http.MethodPut,
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "admin", utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.UpdateAccountStatusTxParams{
+					AccountID: account.ID,
+					From:      db.AccountStatusActive,
+					Status:    db.AccountStatusFrozen,
+				}
+
+				frozen := account
+				frozen.Status = db.AccountStatusFrozen
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(frozen, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				frozen := account
+				frozen.Status = db.AccountStatusFrozen
+				requireBodyMatchAccount(t, recorder.Body, frozen)
+			},
+		},
+		{
+			name:      "Unfreeze",
+			method:    http.MethodDelete,
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "admin", utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.UpdateAccountStatusTxParams{
+					AccountID: account.ID,
+					From:      db.AccountStatusFrozen,
+					Status:    db.AccountStatusActive,
+				}
+
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:      "BankerForbidden",
+			method:    http.MethodPut,
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "OwnerForbidden",
+			method:    http.MethodDelete,
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			method:    http.MethodPut,
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "admin", utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "FreezeClosed",
+			method:    http.MethodPut,
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "admin", utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).Return(db.Account{}, db.ErrInvalidStatusTransition)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			method:    http.MethodPut,
+			accountID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "admin", utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/freeze", tc.accountID)
+			request, err := http.NewRequest(tc.method, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenCreator)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestCloseAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	closed := account
+	closed.Status = db.AccountStatusClosed
+	closed.ClosedAt = sql.NullTime{Time: time.Now().UTC().Truncate(time.Second), Valid: true}
+
+	testCases := []struct {
+		name          string
+		action        string
+		setupAuth     func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator)
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Close",
+			action: "close",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.UpdateAccountStatusTxParams{
+					AccountID: account.ID,
+					From:      db.AccountStatusActive,
+					Status:    db.AccountStatusClosed,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(closed, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp struct {
+					Status   string     `json:"status"`
+					ClosedAt *time.Time `json:"closed_at"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, db.AccountStatusClosed, rsp.Status)
+				require.NotNil(t, rsp.ClosedAt)
+			},
+		},
+		{
+			name:   "CloseNotEmpty",
+			action: "close",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).Return(db.Account{}, db.ErrAccountNotEmpty)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "CloseOtherUser",
+			action: "close",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "unauthorized_user", utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "CloseBankerUnauthorized",
+			action: "close",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "Reopen",
+			action: "reopen",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.UpdateAccountStatusTxParams{
+					AccountID: account.ID,
+					From:      db.AccountStatusClosed,
+					Status:    db.AccountStatusActive,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(closed, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(account, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:   "ReopenFrozen",
+			action: "reopen",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				frozen := account
+				frozen.Status = db.AccountStatusFrozen
+
+				// Reopening only moves closed accounts, so the transaction
+				// rejects it for a frozen one.
+				arg := db.UpdateAccountStatusTxParams{
+					AccountID: account.ID,
+					From:      db.AccountStatusClosed,
+					Status:    db.AccountStatusActive,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(frozen, nil)
+				store.EXPECT().
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(db.Account{}, db.ErrInvalidStatusTransition)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/%s", account.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenCreator)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
 func randomAccount(owner string) db.Account {
 	return db.Account{
 		ID:       utils.RandomInt(1, 1000),
 		Owner:    owner,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
+		Status:   db.AccountStatusActive,
 	}
 }
 
@@ -460,3 +859,37 @@ func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Ac
 	require.NoError(t, err)
 	require.Equal(t, accounts, gotAccounts)
 }
+
+func TestGetAccountAPIDecimalAmounts(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = utils.USD
+	account.Balance = 1050
+	account.OverdraftLimit = 5
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).Return(account, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/accounts/%d?amount_format=decimal", account.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var gotAccount map[string]interface{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &gotAccount)
+	require.NoError(t, err)
+	require.Equal(t, "10.50", gotAccount["balance"])
+	require.Equal(t, "0.05", gotAccount["overdraft_limit"])
+	require.Equal(t, utils.USD, gotAccount["currency"])
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wenealves10/gobank/utils"
+)
+
+// amountFormatQuery selects how amounts are written in responses: "minor"
+// (the default) writes integers in minor units, "decimal" writes strings
+// such as "10.50" using the currency's exponent.
+const amountFormatQuery = "amount_format"
+
+func decimalAmounts(ctx *gin.Context) bool {
+	return ctx.Query(amountFormatQuery) == "decimal"
+}
+
+// money is an amount in minor units of a currency.
+type money struct {
+	amount   int64
+	currency string
+	decimal  bool
+}
+
+func newMoney(amount int64, currency string, decimal bool) money {
+	return money{amount: amount, currency: currency, decimal: decimal}
+}
+
+func (m money) MarshalJSON() ([]byte, error) {
+	if m.decimal {
+		if currency, ok := utils.Currencies.Get(m.currency); ok {
+			return json.Marshal(currency.FormatAmount(m.amount))
+		}
+	}
+	return json.Marshal(m.amount)
+}
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+)
+
+// apiKeyMarker starts every API key, so that leaked keys are easy to spot.
+const apiKeyMarker = "gbk_"
+
+var (
+	errInvalidAPIKey = errors.New("invalid API key")
+	errRevokedAPIKey = errors.New("API key has been revoked")
+	errExpiredAPIKey = errors.New("API key is expired")
+)
+
+type apiKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newAPIKeyResponse(apiKey db.ApiKey) apiKeyResponse {
+	rsp := apiKeyResponse{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Scopes:    apiKey.Scopes,
+		CreatedAt: apiKey.CreatedAt,
+	}
+
+	if apiKey.ExpiresAt.Valid {
+		rsp.ExpiresAt = &apiKey.ExpiresAt.Time
+	}
+
+	if apiKey.LastUsedAt.Valid {
+		rsp.LastUsedAt = &apiKey.LastUsedAt.Time
+	}
+
+	if apiKey.RevokedAt.Valid {
+		rsp.RevokedAt = &apiKey.RevokedAt.Time
+	}
+
+	return rsp
+}
+
+type createAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+	// Scopes must be given, so that no key can do everything its user can.
+	// Keys cannot manage the user's profile.
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,scope,ne=profile"`
+	// ExpiresAt is optional. Keys without it are valid until revoked.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type createAPIKeyResponse struct {
+	// Key is shown once. Only its hash is stored.
+	Key    string         `json:"key"`
+	APIKey apiKeyResponse `json:"api_key"`
+}
+
+// createAPIKey issues an API key that authenticates as the authenticated
+// user with the "ApiKey" authorization type, for jobs that cannot log in.
+func (s *Server) createAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var expiresAt sql.NullTime
+	if !req.ExpiresAt.IsZero() {
+		if !req.ExpiresAt.After(time.Now()) {
+			err := errors.New("expires_at must be in the future")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		expiresAt = sql.NullTime{Time: req.ExpiresAt, Valid: true}
+	}
+
+	prefix, err := utils.NewAPIKeyPrefix()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	prefix = apiKeyMarker + prefix
+
+	secret, err := utils.NewSecretCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	apiKey, err := s.store.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+		Username:   authPayload.Username,
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: utils.HashSecretCode(secret),
+		Scopes:     req.Scopes,
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createAPIKeyResponse{
+		Key:    prefix + "." + secret,
+		APIKey: newAPIKeyResponse(apiKey),
+	})
+}
+
+func (s *Server) listAPIKeys(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	apiKeys, err := s.store.ListAPIKeys(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]apiKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		rsp[i] = newAPIKeyResponse(apiKey)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type apiKeyURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// revokeAPIKey revokes an API key of the authenticated user for good.
+// Revoking a revoked key changes nothing.
+func (s *Server) revokeAPIKey(ctx *gin.Context) {
+	var uri apiKeyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := s.ownedAPIKey(ctx, uri.ID); !valid {
+		return
+	}
+
+	apiKey, err := s.store.RevokeAPIKey(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAPIKeyResponse(apiKey))
+}
+
+type listAPIKeyUsagesRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+type apiKeyUsageResponse struct {
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	ClientIP  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// listAPIKeyUsages lists the requests made with an API key of the
+// authenticated user, most recent first.
+func (s *Server) listAPIKeyUsages(ctx *gin.Context) {
+	var uri apiKeyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listAPIKeyUsagesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := s.ownedAPIKey(ctx, uri.ID); !valid {
+		return
+	}
+
+	usages, err := s.store.ListAPIKeyUsages(ctx, db.ListAPIKeyUsagesParams{
+		ApiKeyID: uri.ID,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]apiKeyUsageResponse, len(usages))
+	for i, usage := range usages {
+		rsp[i] = apiKeyUsageResponse{
+			Method:    usage.Method,
+			Path:      usage.Path,
+			ClientIP:  usage.ClientIp,
+			UserAgent: usage.UserAgent,
+			CreatedAt: usage.CreatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// ownedAPIKey loads an API key of the authenticated user, writing the error
+// response when it cannot.
+func (s *Server) ownedAPIKey(ctx *gin.Context, id int64) (db.ApiKey, bool) {
+	apiKey, err := s.store.GetAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return apiKey, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return apiKey, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if apiKey.Username != authPayload.Username {
+		err := errors.New("API key doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return apiKey, false
+	}
+
+	return apiKey, true
+}
+
+// verifyAPIKey authenticates a request made with an API key and records
+// the use of the key. It returns the payload the request is authorized
+// with, or the status and error to answer with.
+func verifyAPIKey(ctx *gin.Context, store db.Store, key string) (*token.Payload, int, error) {
+	prefix, secret, ok := strings.Cut(key, ".")
+	if !ok || !strings.HasPrefix(prefix, apiKeyMarker) {
+		return nil, http.StatusUnauthorized, errInvalidAPIKey
+	}
+
+	apiKey, err := store.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, http.StatusUnauthorized, errInvalidAPIKey
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	secretHash := utils.HashSecretCode(secret)
+	if subtle.ConstantTimeCompare([]byte(secretHash), []byte(apiKey.SecretHash)) != 1 {
+		return nil, http.StatusUnauthorized, errInvalidAPIKey
+	}
+
+	if apiKey.RevokedAt.Valid {
+		return nil, http.StatusUnauthorized, errRevokedAPIKey
+	}
+
+	if apiKey.ExpiresAt.Valid && time.Now().After(apiKey.ExpiresAt.Time) {
+		return nil, http.StatusUnauthorized, errExpiredAPIKey
+	}
+
+	// The role is looked up on every request, so that a key never keeps a
+	// role its user lost.
+	user, err := store.GetUser(ctx, apiKey.Username)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	err = store.RecordAPIKeyUsage(ctx, db.RecordAPIKeyUsageParams{
+		ApiKeyID:  apiKey.ID,
+		Method:    ctx.Request.Method,
+		Path:      ctx.Request.URL.Path,
+		ClientIp:  ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &token.Payload{
+		Username:  user.Username,
+		Role:      user.Role,
+		Type:      token.TypeAccess,
+		Scopes:    apiKey.Scopes,
+		IssuedAt:  apiKey.CreatedAt,
+		NotBefore: apiKey.CreatedAt,
+		ExpiredAt: apiKey.ExpiresAt.Time,
+	}, http.StatusOK, nil
+}
@@ -0,0 +1,608 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateAPIKeyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.Role = utils.BankerRole
+	scopes := []string{scopeAccountsRead, scopeTransfersWrite}
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	apiKey, key := randomAPIKey(t, user.Username, scopes...)
+
+	var created db.CreateAPIKeyParams
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator)
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": scopes, "expires_at": expiresAt},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						created = arg
+						return db.ApiKey{
+							ID:         1,
+							Username:   arg.Username,
+							Name:       arg.Name,
+							Prefix:     arg.Prefix,
+							SecretHash: arg.SecretHash,
+							Scopes:     arg.Scopes,
+							ExpiresAt:  arg.ExpiresAt,
+							CreatedAt:  time.Now(),
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp createAPIKeyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+
+				require.Equal(t, user.Username, created.Username)
+				require.Equal(t, "nightly-reconciliation", created.Name)
+				require.Equal(t, scopes, created.Scopes)
+				require.True(t, created.ExpiresAt.Valid)
+				require.True(t, expiresAt.Equal(created.ExpiresAt.Time))
+
+				// Only the hash of the secret is stored.
+				prefix, secret, ok := strings.Cut(rsp.Key, ".")
+				require.True(t, ok)
+				require.Equal(t, created.Prefix, prefix)
+				require.True(t, strings.HasPrefix(prefix, apiKeyMarker))
+				require.Equal(t, utils.HashSecretCode(secret), created.SecretHash)
+				require.NotContains(t, recorder.Body.String(), created.SecretHash)
+
+				require.Equal(t, prefix, rsp.APIKey.Prefix)
+				require.Equal(t, scopes, rsp.APIKey.Scopes)
+			},
+		},
+		{
+			name: "NoExpiry",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": scopes},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+						require.False(t, arg.ExpiresAt.Valid)
+						return apiKey, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NoScopes",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": []string{}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidScope",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": []string{scopeAPIKeys}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ProfileScope",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": []string{scopeProfile}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiryInPast",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": scopes, "expires_at": time.Now().Add(-time.Hour)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ScopedToken",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": scopes},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addScopedAuthorization(t, request, tokenCreator, user.Username, scopeProfile, scopeAccountsRead, scopeTransfersWrite)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "APIKey",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": scopes},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAPIKeyAuthorization(request, key)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				expectAPIKeyUse(store, apiKey, user)
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": scopes},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"name": "nightly-reconciliation", "scopes": scopes},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/api_keys", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenCreator)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListAPIKeysAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	apiKeys := make([]db.ApiKey, 2)
+	for i := range apiKeys {
+		apiKeys[i], _ = randomAPIKey(t, user.Username, scopeAccountsRead)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAPIKeys(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(apiKeys, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/users/me/api_keys", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp []apiKeyResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	require.Len(t, rsp, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		require.Equal(t, apiKey.ID, rsp[i].ID)
+		require.Equal(t, apiKey.Prefix, rsp[i].Prefix)
+	}
+	require.NotContains(t, recorder.Body.String(), apiKeys[0].SecretHash)
+}
+
+func TestRevokeAPIKeyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	apiKey, _ := randomAPIKey(t, user.Username, scopeAccountsRead)
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mocks.MockStore) {
+				revoked := apiKey
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(apiKey, nil)
+				store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(revoked, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp apiKeyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotNil(t, rsp.RevokedAt)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(db.ApiKey{}, sql.ErrNoRows)
+				store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUser",
+			username: utils.RandomOwner(),
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(apiKey, nil)
+				store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user.Username,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(apiKey, nil)
+				store.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(db.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/me/api_keys/%d", apiKey.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, tc.username, utils.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListAPIKeyUsagesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	apiKey, _ := randomAPIKey(t, user.Username, scopeAccountsRead)
+
+	usages := []db.ApiKeyUsage{
+		{
+			ID:        2,
+			ApiKeyID:  apiKey.ID,
+			Method:    http.MethodGet,
+			Path:      "/accounts",
+			ClientIp:  "10.0.0.1",
+			UserAgent: "batch-job/1.0",
+			CreatedAt: time.Now(),
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().GetAPIKey(gomock.Any(), gomock.Eq(apiKey.ID)).Times(1).Return(apiKey, nil)
+	store.EXPECT().
+		ListAPIKeyUsages(gomock.Any(), gomock.Eq(db.ListAPIKeyUsagesParams{
+			ApiKeyID: apiKey.ID,
+			Limit:    5,
+			Offset:   5,
+		})).
+		Times(1).
+		Return(usages, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/users/me/api_keys/%d/usages?page_id=2&page_size=5", apiKey.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp []apiKeyUsageResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	require.Len(t, rsp, 1)
+	require.Equal(t, usages[0].Path, rsp[0].Path)
+	require.Equal(t, usages[0].ClientIp, rsp[0].ClientIP)
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	user, _ := randomUser(t)
+	user.Role = utils.BankerRole
+
+	apiKey, key := randomAPIKey(t, user.Username, scopeAccountsRead)
+
+	revoked := apiKey
+	revoked.RevokedAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+
+	expired := apiKey
+	expired.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+
+	unscoped, unscopedKey := randomAPIKey(t, user.Username, scopeTransfersRead)
+
+	testCases := []struct {
+		name          string
+		key           string
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			key:  key,
+			buildStubs: func(store *mocks.MockStore) {
+				expectAPIKeyUse(store, apiKey, user)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var payload token.Payload
+				err := json.Unmarshal(recorder.Body.Bytes(), &payload)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, user.Role, payload.Role)
+				require.Equal(t, apiKey.Scopes, payload.Scopes)
+			},
+		},
+		{
+			name: "WrongSecret",
+			key:  apiKey.Prefix + ".wrong-secret",
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).Times(1).Return(apiKey, nil)
+				store.EXPECT().RecordAPIKeyUsage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownPrefix",
+			key:  key,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).Times(1).Return(db.ApiKey{}, sql.ErrNoRows)
+				store.EXPECT().RecordAPIKeyUsage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Malformed",
+			key:  strings.Replace(key, ".", "", 1),
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Revoked",
+			key:  key,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).Times(1).Return(revoked, nil)
+				store.EXPECT().RecordAPIKeyUsage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Expired",
+			key:  key,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).Times(1).Return(expired, nil)
+				store.EXPECT().RecordAPIKeyUsage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MissingScope",
+			key:  unscopedKey,
+			buildStubs: func(store *mocks.MockStore) {
+				expectAPIKeyUse(store, unscoped, user)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "RecordUsageError",
+			key:  key,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAPIKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).Times(1).Return(apiKey, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().RecordAPIKeyUsage(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+
+			authPath := "/auth"
+			server.router.GET(authPath, authMiddleware(server.tokenCreator, server.revocations, server.store, scopeAccountsRead), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, ctx.MustGet(authorizationPayloadKey))
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			addAPIKeyAuthorization(request, tc.key)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+// randomAPIKey returns an API key of username and the key that
+// authenticates with it.
+func randomAPIKey(t *testing.T, username string, scopes ...string) (db.ApiKey, string) {
+	prefix, err := utils.NewAPIKeyPrefix()
+	require.NoError(t, err)
+
+	secret, err := utils.NewSecretCode()
+	require.NoError(t, err)
+
+	apiKey := db.ApiKey{
+		ID:         utils.RandomInt(1, 1000),
+		Username:   username,
+		Name:       utils.RandomString(8),
+		Prefix:     apiKeyMarker + prefix,
+		SecretHash: utils.HashSecretCode(secret),
+		Scopes:     scopes,
+		CreatedAt:  time.Now(),
+	}
+	return apiKey, apiKey.Prefix + "." + secret
+}
+
+func addAPIKeyAuthorization(request *http.Request, key string) {
+	request.Header.Set(authorizationHeaderKey, "ApiKey "+key)
+}
+
+// expectAPIKeyUse expects a request authenticated with apiKey, which is
+// recorded.
+func expectAPIKeyUse(store *mocks.MockStore, apiKey db.ApiKey, user db.User) {
+	store.EXPECT().
+		GetAPIKeyByPrefix(gomock.Any(), gomock.Eq(apiKey.Prefix)).
+		Times(1).
+		Return(apiKey, nil)
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+
+	store.EXPECT().
+		RecordAPIKeyUsage(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.RecordAPIKeyUsageParams) error {
+			if arg.ApiKeyID != apiKey.ID || arg.Method == "" || arg.Path == "" {
+				return fmt.Errorf("unexpected usage %+v", arg)
+			}
+			return nil
+		})
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/wenealves10/gobank/db/sqlc"
+)
+
+const (
+	requestIDHeaderKey = "X-Request-ID"
+	// maxRequestIDLength bounds the request ids taken from clients.
+	maxRequestIDLength = 128
+)
+
+// auditMiddleware identifies every request for the audit events recorded
+// while serving it. A request id sent by the client is kept, so that its
+// events can be found by it; otherwise one is generated. Either way it is
+// echoed in the response.
+func auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeaderKey)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.New().String()
+		}
+		ctx.Header(requestIDHeaderKey, requestID)
+
+		auditCtx := db.WithAuditContext(ctx.Request.Context(), db.AuditContext{
+			RequestID: requestID,
+			ClientIP:  ctx.ClientIP(),
+			UserAgent: ctx.Request.UserAgent(),
+		})
+		ctx.Request = ctx.Request.WithContext(auditCtx)
+
+		ctx.Next()
+	}
+}
+
+type listAuditEventsQuery struct {
+	Actor        string    `form:"actor"`
+	Action       string    `form:"action"`
+	ResourceType string    `form:"resource_type"`
+	ResourceID   string    `form:"resource_id"`
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor       string    `form:"cursor"`
+	PageSize     int32     `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+func (req listAuditEventsQuery) params() (db.ListAuditEventsParams, error) {
+	arg := db.ListAuditEventsParams{
+		Actor:        sql.NullString{String: req.Actor, Valid: req.Actor != ""},
+		Action:       sql.NullString{String: req.Action, Valid: req.Action != ""},
+		ResourceType: sql.NullString{String: req.ResourceType, Valid: req.ResourceType != ""},
+		ResourceID:   sql.NullString{String: req.ResourceID, Valid: req.ResourceID != ""},
+		FromTime:     sql.NullTime{Time: req.From, Valid: !req.From.IsZero()},
+		ToTime:       sql.NullTime{Time: req.To, Valid: !req.To.IsZero()},
+		PageSize:     req.PageSize,
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return arg, errors.New("from must be before to")
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeKeysetCursor(req.Cursor)
+		if err != nil {
+			return arg, err
+		}
+
+		arg.CursorCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+		arg.CursorID = sql.NullInt64{Int64: cursor.ID, Valid: true}
+	}
+
+	return arg, nil
+}
+
+type auditEventResponse struct {
+	ID           int64           `json:"id"`
+	Actor        string          `json:"actor"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	RequestID    string          `json:"request_id"`
+	ClientIP     string          `json:"client_ip"`
+	UserAgent    string          `json:"user_agent"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	PrevHash     string          `json:"prev_hash"`
+	Hash         string          `json:"hash"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+func newAuditEventResponse(event db.AuditEvent) auditEventResponse {
+	return auditEventResponse{
+		ID:           event.ID,
+		Actor:        event.Actor,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		RequestID:    event.RequestID,
+		ClientIP:     event.ClientIp,
+		UserAgent:    event.UserAgent,
+		Before:       event.Before,
+		After:        event.After,
+		PrevHash:     event.PrevHash,
+		Hash:         event.Hash,
+		CreatedAt:    event.CreatedAt,
+	}
+}
+
+type listAuditEventsResponse struct {
+	Events     []auditEventResponse `json:"events"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// listAuditEvents lists the audit events matching the filters, most recent
+// first.
+func (s *Server) listAuditEvents(ctx *gin.Context) {
+	var req listAuditEventsQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	events, err := s.store.ListAuditEvents(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := listAuditEventsResponse{
+		Events: make([]auditEventResponse, len(events)),
+	}
+	for i, event := range events {
+		rsp.Events[i] = newAuditEventResponse(event)
+	}
+
+	if len(events) == int(req.PageSize) {
+		last := events[len(events)-1]
+		rsp.NextCursor = keysetCursor{CreatedAt: last.CreatedAt, ID: last.ID}.encode()
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type verifyAuditEventsResponse struct {
+	Valid    bool   `json:"valid"`
+	Events   int64  `json:"events"`
+	LastHash string `json:"last_hash"`
+	// BrokenEventID is the first event that does not match the chain.
+	BrokenEventID int64 `json:"broken_event_id,omitempty"`
+}
+
+// verifyAuditEvents checks the hash chain of the whole audit log.
+func (s *Server) verifyAuditEvents(ctx *gin.Context) {
+	result, err := db.VerifyAuditChain(ctx, s.store)
+
+	var chainErr *db.AuditChainError
+	if errors.As(err, &chainErr) {
+		ctx.JSON(http.StatusOK, verifyAuditEventsResponse{
+			Events:        result.Events,
+			LastHash:      result.LastHash,
+			BrokenEventID: chainErr.EventID,
+		})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verifyAuditEventsResponse{
+		Valid:    true,
+		Events:   result.Events,
+		LastHash: result.LastHash,
+	})
+}
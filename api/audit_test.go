@@ -0,0 +1,405 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListAuditEventsAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+
+	n := 5
+	events := make([]db.AuditEvent, n)
+	for i := 0; i < n; i++ {
+		events[i] = randomAuditEvent()
+	}
+
+	cursor := keysetCursor{CreatedAt: events[0].CreatedAt, ID: events[0].ID}
+	from := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         map[string]string
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: utils.AdminRole,
+			query: map[string]string{
+				"page_size":     fmt.Sprintf("%d", n),
+				"actor":         events[0].Actor,
+				"resource_type": db.AuditResourceAccount,
+				"resource_id":   "42",
+				"from":          from.Format(time.RFC3339),
+				"cursor":        cursor.encode(),
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.ListAuditEventsParams{
+					Actor:           sql.NullString{String: events[0].Actor, Valid: true},
+					ResourceType:    sql.NullString{String: db.AuditResourceAccount, Valid: true},
+					ResourceID:      sql.NullString{String: "42", Valid: true},
+					FromTime:        sql.NullTime{Time: from, Valid: true},
+					CursorCreatedAt: sql.NullTime{Time: cursor.CreatedAt, Valid: true},
+					CursorID:        sql.NullInt64{Int64: cursor.ID, Valid: true},
+					PageSize:        int32(n),
+				}
+
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(arg)).Times(1).Return(events, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAuditEvents(t, recorder.Body, events, true)
+			},
+		},
+		{
+			name: "LastPage",
+			role: utils.AdminRole,
+			query: map[string]string{
+				"page_size": fmt.Sprintf("%d", n+1),
+				"action":    "transfer.create",
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.ListAuditEventsParams{
+					Action:   sql.NullString{String: "transfer.create", Valid: true},
+					PageSize: int32(n + 1),
+				}
+
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(arg)).Times(1).Return(events, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAuditEvents(t, recorder.Body, events, false)
+			},
+		},
+		{
+			name: "Forbidden",
+			role: utils.BankerRole,
+			query: map[string]string{
+				"page_size": fmt.Sprintf("%d", n),
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidPageSize",
+			role: utils.AdminRole,
+			query: map[string]string{
+				"page_size": "1000",
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTimeRange",
+			role: utils.AdminRole,
+			query: map[string]string{
+				"page_size": fmt.Sprintf("%d", n),
+				"from":      from.Format(time.RFC3339),
+				"to":        from.Add(-time.Hour).Format(time.RFC3339),
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCursor",
+			role: utils.AdminRole,
+			query: map[string]string{
+				"page_size": fmt.Sprintf("%d", n),
+				"cursor":    "not-a-cursor",
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			role: utils.AdminRole,
+			query: map[string]string{
+				"page_size": fmt.Sprintf("%d", n),
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(1).Return([]db.AuditEvent{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/audit_events", nil)
+			require.NoError(t, err)
+
+			query := request.URL.Query()
+			for key, value := range tc.query {
+				query.Add(key, value)
+			}
+			request.URL.RawQuery = query.Encode()
+
+			addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, admin.Username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestVerifyAuditEventsAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+
+	broken := randomAuditEvent()
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Valid",
+			role: utils.AdminRole,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					ListAuditEventsAfter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.AuditEvent{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := decodeVerifyAuditEventsResponse(t, recorder.Body)
+				require.True(t, rsp.Valid)
+				require.Zero(t, rsp.Events)
+				require.Len(t, rsp.LastHash, 64)
+				require.Zero(t, rsp.BrokenEventID)
+			},
+		},
+		{
+			name: "Broken",
+			role: utils.AdminRole,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					ListAuditEventsAfter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.AuditEvent{broken}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := decodeVerifyAuditEventsResponse(t, recorder.Body)
+				require.False(t, rsp.Valid)
+				require.Zero(t, rsp.Events)
+				require.Equal(t, broken.ID, rsp.BrokenEventID)
+			},
+		},
+		{
+			name: "Forbidden",
+			role: utils.DepositorRole,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().ListAuditEventsAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			role: utils.AdminRole,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					ListAuditEventsAfter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.AuditEvent{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/audit_events/verify", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, admin.Username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+// TestAuditContext checks that the store is called with the audit context
+// of the request, so that the events it records name who made it.
+func TestAuditContext(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name      string
+		requestID string
+		check     func(t *testing.T, requestID string, audit db.AuditContext)
+	}{
+		{
+			name:      "ClientRequestID",
+			requestID: "req-" + utils.RandomString(12),
+			check: func(t *testing.T, requestID string, audit db.AuditContext) {
+				require.Equal(t, requestID, audit.RequestID)
+			},
+		},
+		{
+			name: "GeneratedRequestID",
+			check: func(t *testing.T, requestID string, audit db.AuditContext) {
+				require.Len(t, audit.RequestID, 36)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var audit db.AuditContext
+
+			store := mocks.NewMockStore(ctrl)
+			store.EXPECT().
+				CreateAccount(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, _ db.CreateAccountParams) (db.Account, error) {
+					audit = db.AuditContextFrom(ctx)
+					return account, nil
+				})
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, err := json.Marshal(gin.H{"currency": account.Currency})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(body))
+			require.NoError(t, err)
+			request.Header.Set("User-Agent", "audit-test/1.0")
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeaderKey, tc.requestID)
+			}
+
+			addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			require.Equal(t, user.Username, audit.Actor)
+			require.Equal(t, "audit-test/1.0", audit.UserAgent)
+			require.Equal(t, audit.RequestID, recorder.Header().Get(requestIDHeaderKey))
+			tc.check(t, tc.requestID, audit)
+		})
+	}
+}
+
+func randomAuditEvent() db.AuditEvent {
+	return db.AuditEvent{
+		ID:           utils.RandomInt(1, 1000),
+		Actor:        utils.RandomOwner(),
+		Action:       "account.create",
+		ResourceType: db.AuditResourceAccount,
+		ResourceID:   fmt.Sprintf("%d", utils.RandomInt(1, 1000)),
+		RequestID:    utils.RandomString(12),
+		ClientIp:     "10.0.0.1",
+		UserAgent:    "test",
+		Before:       json.RawMessage(`null`),
+		After:        json.RawMessage(`{"balance":0}`),
+		PrevHash:     utils.RandomString(64),
+		Hash:         utils.RandomString(64),
+		CreatedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func requireBodyMatchAuditEvents(t *testing.T, body *bytes.Buffer, events []db.AuditEvent, hasNext bool) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var rsp listAuditEventsResponse
+	err = json.Unmarshal(data, &rsp)
+	require.NoError(t, err)
+
+	require.Len(t, rsp.Events, len(events))
+	for i, event := range events {
+		require.Equal(t, event.ID, rsp.Events[i].ID)
+		require.Equal(t, event.Actor, rsp.Events[i].Actor)
+		require.Equal(t, event.Hash, rsp.Events[i].Hash)
+		require.JSONEq(t, string(event.After), string(rsp.Events[i].After))
+	}
+
+	if hasNext {
+		last := events[len(events)-1]
+		require.Equal(t, keysetCursor{CreatedAt: last.CreatedAt, ID: last.ID}.encode(), rsp.NextCursor)
+	} else {
+		require.Empty(t, rsp.NextCursor)
+	}
+}
+
+func decodeVerifyAuditEventsResponse(t *testing.T, body *bytes.Buffer) verifyAuditEventsResponse {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var rsp verifyAuditEventsResponse
+	err = json.Unmarshal(data, &rsp)
+	require.NoError(t, err)
+	return rsp
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+)
+
+// permission names an action on accounts. Handlers declare the permission
+// they need instead of comparing owners themselves.
+type permission string
+
+const (
+	permReadAccount     permission = "accounts:read"
+	permTransferFrom    permission = "accounts:transfer"
+	permFreezeAccount   permission = "accounts:freeze"
+	permListAnyAccounts permission = "accounts:list_any"
+	permMoveCash        permission = "accounts:cash"
+	permRefundTransfer  permission = "transfers:refund"
+	permForceReversal   permission = "transfers:force_reverse"
+	permSettleHold      permission = "holds:settle"
+	permCloseAccount    permission = "accounts:close"
+	permReadAuditLog    permission = "audit:read"
+)
+
+// Scopes restrict what a token may be used for, whatever the role of its
+// user allows. Tokens without scopes are not restricted.
+const (
+	scopeProfile        = "profile"
+	scopeAccountsRead   = "accounts:read"
+	scopeAccountsWrite  = "accounts:write"
+	scopeTransfersRead  = "transfers:read"
+	scopeTransfersWrite = "transfers:write"
+	scopeAuditRead      = "audit:read"
+	// scopeAPIKeys is not valid to ask for, so only tokens without scopes
+	// grant it.
+	scopeAPIKeys = "api_keys"
+)
+
+func isValidScope(scope string) bool {
+	switch scope {
+	case scopeProfile, scopeAccountsRead, scopeAccountsWrite, scopeTransfersRead, scopeTransfersWrite, scopeAuditRead:
+		return true
+	}
+	return false
+}
+
+// ownerPermissions are granted to the owner of an account, whatever their role.
+var ownerPermissions = []permission{
+	permReadAccount,
+	permTransferFrom,
+	permRefundTransfer,
+	permSettleHold,
+	permCloseAccount,
+}
+
+// rolePermissions are granted on every account to users with the role.
+var rolePermissions = map[string][]permission{
+	utils.DepositorRole: {},
+	utils.BankerRole: {
+		permReadAccount,
+		permListAnyAccounts,
+		permMoveCash,
+	},
+	utils.AdminRole: {
+		permReadAccount,
+		permListAnyAccounts,
+		permFreezeAccount,
+		permMoveCash,
+		permRefundTransfer,
+		permForceReversal,
+		permSettleHold,
+		permCloseAccount,
+		permReadAuditLog,
+	},
+}
+
+func hasPermission(permissions []permission, perm permission) bool {
+	for _, p := range permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// roleAllows reports whether the caller's role grants perm on every account.
+func roleAllows(payload *token.Payload, perm permission) bool {
+	return hasPermission(rolePermissions[payload.Role], perm)
+}
+
+// canAccessAccount reports whether the caller may perform perm on account,
+// either as its owner or through their role.
+func canAccessAccount(payload *token.Payload, account db.Account, perm permission) bool {
+	if account.Owner == payload.Username && hasPermission(ownerPermissions, perm) {
+		return true
+	}
+	return roleAllows(payload, perm)
+}
+
+// requirePermission rejects callers whose role does not grant perm. It must
+// run after authMiddleware.
+func requirePermission(perm permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if !roleAllows(authPayload, perm) {
+			err := fmt.Errorf("role %q is not allowed to %s", authPayload.Role, perm)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// requireVerifiedEmail rejects callers who have not verified their email
+// yet. It must run after authMiddleware.
+func requireVerifiedEmail(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		verified, err := store.IsUserEmailVerified(ctx, authPayload.Username)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if !verified {
+			err := errors.New("email address is not verified")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// authorizedAccount loads an account and checks that the authenticated user
+// may perform perm on it, writing the error response when they may not.
+func (s *Server) authorizedAccount(ctx *gin.Context, accountID int64, perm permission) (db.Account, bool) {
+	account, valid := s.loadAccount(ctx, accountID)
+	if !valid {
+		return account, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canAccessAccount(authPayload, account, perm) {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+// loadAccount fetches an account, writing the error response when it
+// cannot.
+func (s *Server) loadAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/wenealves10/gobank/db/sqlc"
+)
+
+type cashAccountURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type cashRequest struct {
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Reference   string `json:"reference" binding:"required,max=64"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+type cashEntryResponse struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	Amount      money     `json:"amount"`
+	Kind        string    `json:"kind"`
+	Reference   string    `json:"reference"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newCashEntryResponse(entry db.Entry, currency string, decimal bool) cashEntryResponse {
+	return cashEntryResponse{
+		ID:          entry.ID,
+		AccountID:   entry.AccountID,
+		Amount:      newMoney(entry.Amount, currency, decimal),
+		Kind:        entry.Kind,
+		Reference:   entry.Reference,
+		Description: entry.Description,
+		CreatedAt:   entry.CreatedAt,
+	}
+}
+
+type cashTxResponse struct {
+	Account accountResponse   `json:"account"`
+	Entry   cashEntryResponse `json:"entry"`
+}
+
+func newCashTxResponse(result db.CashTxResult, decimal bool) cashTxResponse {
+	return cashTxResponse{
+		Account: newAccountResponse(result.Account, decimal),
+		Entry:   newCashEntryResponse(result.Entry, result.Account.Currency, decimal),
+	}
+}
+
+func (s *Server) depositAccount(ctx *gin.Context) {
+	s.moveCash(ctx, s.store.DepositTx)
+}
+
+func (s *Server) withdrawAccount(ctx *gin.Context) {
+	s.moveCash(ctx, s.store.WithdrawTx)
+}
+
+// moveCash runs a deposit or withdrawal against the account in the URI.
+func (s *Server) moveCash(ctx *gin.Context, cashTx func(context.Context, db.CashTxParams) (db.CashTxResult, error)) {
+	var uri cashAccountURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req cashRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := cashTx(ctx, db.CashTxParams{
+		AccountID:   uri.ID,
+		Amount:      req.Amount,
+		Reference:   req.Reference,
+		Description: req.Description,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrCashAccountNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) || errors.Is(err, db.ErrSystemAccount) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newCashTxResponse(result, decimalAmounts(ctx)))
+}
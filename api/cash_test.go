@@ -0,0 +1,330 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCashAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	amount := int64(100)
+	reference := utils.RandomString(12)
+
+	depositArg := db.CashTxParams{
+		AccountID:   account.ID,
+		Amount:      amount,
+		Reference:   reference,
+		Description: "cash deposit",
+	}
+
+	depositResult := db.CashTxResult{
+		Account: account,
+		Entry: db.Entry{
+			ID:          utils.RandomInt(1, 1000),
+			AccountID:   account.ID,
+			Amount:      amount,
+			Kind:        db.EntryKindDeposit,
+			Reference:   reference,
+			Description: "cash deposit",
+		},
+	}
+	depositResult.Account.Balance += amount
+
+	testCases := []struct {
+		name          string
+		path          string
+		accountID     int64
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator)
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Deposit",
+			path:      "deposits",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":      amount,
+				"reference":   reference,
+				"description": "cash deposit",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Eq(depositArg)).
+					Times(1).Return(depositResult, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchCashTx(t, recorder.Body, depositResult)
+			},
+		},
+		{
+			name:      "Withdrawal",
+			path:      "withdrawals",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reference": reference,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "admin", utils.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.CashTxParams{
+					AccountID: account.ID,
+					Amount:    amount,
+					Reference: reference,
+				}
+
+				result := db.CashTxResult{
+					Account: account,
+					Entry: db.Entry{
+						ID:        utils.RandomInt(1, 1000),
+						AccountID: account.ID,
+						Amount:    -amount,
+						Kind:      db.EntryKindWithdrawal,
+						Reference: reference,
+					},
+				}
+
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(result, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "DepositorForbidden",
+			path:      "deposits",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reference": reference,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			path:      "deposits",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reference": reference,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingReference",
+			path:      "deposits",
+			accountID: account.ID,
+			body: gin.H{
+				"amount": amount,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NegativeAmount",
+			path:      "withdrawals",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    -amount,
+				"reference": reference,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "AccountNotFound",
+			path:      "deposits",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reference": reference,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).Return(db.CashTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InsufficientFunds",
+			path:      "withdrawals",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reference": reference,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).Return(db.CashTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:      "FrozenAccount",
+			path:      "deposits",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reference": reference,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).Return(db.CashTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "SystemAccount",
+			path:      "deposits",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reference": reference,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).Return(db.CashTxResult{}, db.ErrSystemAccount)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			path:      "withdrawals",
+			accountID: account.ID,
+			body: gin.H{
+				"amount":    amount,
+				"reference": reference,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "banker", utils.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).Return(db.CashTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/%s", tc.accountID, tc.path)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenCreator)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func requireBodyMatchCashTx(t *testing.T, body *bytes.Buffer, result db.CashTxResult) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	expected, err := json.Marshal(newCashTxResponse(result, false))
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(data))
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wenealves10/gobank/utils"
+)
+
+type listCurrenciesRequest struct {
+	IncludeDisabled bool `form:"include_disabled"`
+}
+
+func (s *Server) listCurrencies(ctx *gin.Context) {
+	var req listCurrenciesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	currencies := []utils.Currency{}
+	for _, currency := range utils.Currencies.List() {
+		if currency.Enabled || req.IncludeDisabled {
+			currencies = append(currencies, currency)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, currencies)
+}
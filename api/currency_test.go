@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListCurrenciesAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var currencies []utils.Currency
+				err := json.Unmarshal(recorder.Body.Bytes(), &currencies)
+				require.NoError(t, err)
+				require.Len(t, currencies, len(utils.DefaultCurrencies))
+				for _, currency := range currencies {
+					require.True(t, currency.Enabled)
+					require.Equal(t, 2, currency.Exponent)
+				}
+			},
+		},
+		{
+			name:  "InvalidQuery",
+			query: "?include_disabled=maybe",
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/currencies"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
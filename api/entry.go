@@ -0,0 +1,116 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/wenealves10/gobank/db/sqlc"
+)
+
+type listEntriesURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listEntriesQuery struct {
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor   int64     `form:"cursor" binding:"min=0"`
+	PageSize int32     `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// entryResponse is an entry of a statement. TransferID and
+// CounterpartyAccountID are left out for entries not written by a transfer,
+// and for old ones that could not be matched to theirs.
+type entryResponse struct {
+	ID                    int64     `json:"id"`
+	Amount                money     `json:"amount"`
+	RunningBalance        money     `json:"running_balance"`
+	Kind                  string    `json:"kind"`
+	Reference             string    `json:"reference,omitempty"`
+	Description           string    `json:"description,omitempty"`
+	TransferID            *int64    `json:"transfer_id,omitempty"`
+	CounterpartyAccountID *int64    `json:"counterparty_account_id,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+type listEntriesResponse struct {
+	Entries    []entryResponse `json:"entries"`
+	NextCursor int64           `json:"next_cursor,omitempty"`
+}
+
+func newEntryResponse(row db.ListAccountStatementRow, currency string, decimal bool) entryResponse {
+	rsp := entryResponse{
+		ID:             row.ID,
+		Amount:         newMoney(row.Amount, currency, decimal),
+		RunningBalance: newMoney(row.RunningBalance, currency, decimal),
+		Kind:           row.Kind,
+		Reference:      row.Reference,
+		Description:    row.Description,
+		CreatedAt:      row.CreatedAt,
+	}
+
+	if row.TransferID.Valid {
+		rsp.TransferID = &row.TransferID.Int64
+	}
+
+	if row.CounterpartyAccountID.Valid {
+		rsp.CounterpartyAccountID = &row.CounterpartyAccountID.Int64
+	}
+
+	return rsp
+}
+
+func (s *Server) listEntries(ctx *gin.Context) {
+	var uri listEntriesURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listEntriesQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		err := errors.New("from must be before to")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := s.authorizedAccount(ctx, uri.AccountID, permReadAccount)
+	if !valid {
+		return
+	}
+
+	arg := db.ListAccountStatementParams{
+		AccountID: uri.AccountID,
+		FromTime:  sql.NullTime{Time: req.From, Valid: !req.From.IsZero()},
+		ToTime:    sql.NullTime{Time: req.To, Valid: !req.To.IsZero()},
+		AfterID:   req.Cursor,
+		PageSize:  req.PageSize,
+	}
+
+	rows, err := s.store.ListAccountStatement(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := listEntriesResponse{
+		Entries: make([]entryResponse, len(rows)),
+	}
+	for i, row := range rows {
+		rsp.Entries[i] = newEntryResponse(row, account.Currency, decimalAmounts(ctx))
+	}
+
+	if len(rows) == int(req.PageSize) {
+		rsp.NextCursor = rows[len(rows)-1].ID
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
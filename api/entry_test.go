@@ -0,0 +1,284 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListEntriesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	n := 5
+	rows := make([]db.ListAccountStatementRow, n)
+	for i := 0; i < n; i++ {
+		rows[i] = randomStatementRow(account, int64(i+1))
+	}
+	rows[0].Kind = db.EntryKindDeposit
+	rows[0].Reference = utils.RandomString(12)
+	rows[0].Description = "cash deposit"
+	rows[0].TransferID = sql.NullInt64{}
+	rows[0].CounterpartyAccountID = sql.NullInt64{}
+
+	from := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	to := time.Now().UTC().Truncate(time.Second)
+
+	type Query struct {
+		from     string
+		to       string
+		cursor   int64
+		pageSize int
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         Query
+		setupAuth     func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator)
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			query: Query{
+				from:     from.Format(time.RFC3339),
+				to:       to.Format(time.RFC3339),
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.ListAccountStatementParams{
+					AccountID: account.ID,
+					FromTime:  sql.NullTime{Time: from, Valid: true},
+					ToTime:    sql.NullTime{Time: to, Valid: true},
+					AfterID:   0,
+					PageSize:  int32(n),
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListAccountStatement(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(rows, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchStatement(t, recorder.Body, account.Currency, rows, rows[n-1].ID)
+			},
+		},
+		{
+			name:      "LastPage",
+			accountID: account.ID,
+			query: Query{
+				cursor:   rows[1].ID,
+				pageSize: n + 1,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.ListAccountStatementParams{
+					AccountID: account.ID,
+					AfterID:   rows[1].ID,
+					PageSize:  int32(n + 1),
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListAccountStatement(gomock.Any(), gomock.Eq(arg)).
+					Times(1).Return(rows[2:], nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchStatement(t, recorder.Body, account.Currency, rows[2:], 0)
+			},
+		},
+		{
+			name:      "UnauthorizedUser",
+			accountID: account.ID,
+			query: Query{
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "unauthorized_user", utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountStatement(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			query: Query{
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountStatement(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "AccountNotFound",
+			accountID: account.ID,
+			query: Query{
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ListAccountStatement(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidDateRange",
+			accountID: account.ID,
+			query: Query{
+				from:     to.Format(time.RFC3339),
+				to:       from.Format(time.RFC3339),
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountStatement(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPageSize",
+			accountID: account.ID,
+			query: Query{
+				pageSize: 1000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountStatement(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountID: account.ID,
+			query: Query{
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListAccountStatement(gomock.Any(), gomock.Any()).
+					Times(1).Return([]db.ListAccountStatementRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/entries", tc.accountID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			query := request.URL.Query()
+			if tc.query.from != "" {
+				query.Add("from", tc.query.from)
+			}
+			if tc.query.to != "" {
+				query.Add("to", tc.query.to)
+			}
+			query.Add("cursor", fmt.Sprintf("%d", tc.query.cursor))
+			query.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
+			request.URL.RawQuery = query.Encode()
+
+			tc.setupAuth(t, request, server.tokenCreator)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func randomStatementRow(account db.Account, id int64) db.ListAccountStatementRow {
+	return db.ListAccountStatementRow{
+		ID:                    id,
+		AccountID:             account.ID,
+		Amount:                utils.RandomMoney(),
+		Kind:                  db.EntryKindTransfer,
+		TransferID:            sql.NullInt64{Int64: utils.RandomInt(1, 1000), Valid: true},
+		CounterpartyAccountID: sql.NullInt64{Int64: utils.RandomInt(1, 1000), Valid: true},
+		RunningBalance:        utils.RandomMoney(),
+		CreatedAt:             time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func requireBodyMatchStatement(t *testing.T, body *bytes.Buffer, currency string, rows []db.ListAccountStatementRow, nextCursor int64) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotResponse struct {
+		Entries    []json.RawMessage `json:"entries"`
+		NextCursor int64             `json:"next_cursor"`
+	}
+	err = json.Unmarshal(data, &gotResponse)
+	require.NoError(t, err)
+
+	require.Len(t, gotResponse.Entries, len(rows))
+	for i, row := range rows {
+		expected, err := json.Marshal(newEntryResponse(row, currency, false))
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), string(gotResponse.Entries[i]))
+	}
+	require.Equal(t, nextCursor, gotResponse.NextCursor)
+}
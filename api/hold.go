@@ -0,0 +1,342 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
+)
+
+const (
+	// defaultHoldDuration is how long a hold lasts when no expiry is given.
+	defaultHoldDuration = 7 * 24 * time.Hour
+	// maxHoldDuration is the longest a hold may reserve funds for.
+	maxHoldDuration = 30 * 24 * time.Hour
+)
+
+type holdResponse struct {
+	ID          int64      `json:"id"`
+	AccountID   int64      `json:"account_id"`
+	ToAccountID int64      `json:"to_account_id"`
+	Amount      money      `json:"amount"`
+	Status      string     `json:"status"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	TransferID  *int64     `json:"transfer_id,omitempty"`
+	SettledAt   *time.Time `json:"settled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func newHoldResponse(hold db.Hold, currency string, decimal bool) holdResponse {
+	rsp := holdResponse{
+		ID:          hold.ID,
+		AccountID:   hold.AccountID,
+		ToAccountID: hold.ToAccountID,
+		Amount:      newMoney(hold.Amount, currency, decimal),
+		Status:      hold.Status,
+		ExpiresAt:   hold.ExpiresAt,
+		CreatedAt:   hold.CreatedAt,
+	}
+
+	// Holds expire on their own, without their status being updated.
+	if hold.Status == db.HoldStatusActive && !hold.ExpiresAt.After(time.Now()) {
+		rsp.Status = db.HoldStatusExpired
+	}
+
+	if hold.TransferID.Valid {
+		rsp.TransferID = &hold.TransferID.Int64
+	}
+
+	if hold.SettledAt.Valid {
+		rsp.SettledAt = &hold.SettledAt.Time
+	}
+
+	return rsp
+}
+
+type accountBalanceResponse struct {
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+	// LedgerBalance is the booked balance of the account, before holds.
+	LedgerBalance money `json:"ledger_balance"`
+	HeldAmount    money `json:"held_amount"`
+	// AvailableBalance is the ledger balance less the active holds.
+	AvailableBalance money `json:"available_balance"`
+	OverdraftLimit   money `json:"overdraft_limit"`
+}
+
+type accountHoldsURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getAccountBalance(ctx *gin.Context) {
+	var uri accountHoldsURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := s.authorizedAccount(ctx, uri.AccountID, permReadAccount)
+	if !valid {
+		return
+	}
+
+	held, err := s.store.GetAccountHeldAmount(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	decimal := decimalAmounts(ctx)
+	ctx.JSON(http.StatusOK, accountBalanceResponse{
+		AccountID:        account.ID,
+		Currency:         account.Currency,
+		LedgerBalance:    newMoney(account.Balance, account.Currency, decimal),
+		HeldAmount:       newMoney(held, account.Currency, decimal),
+		AvailableBalance: newMoney(account.Balance-held, account.Currency, decimal),
+		OverdraftLimit:   newMoney(account.OverdraftLimit, account.Currency, decimal),
+	})
+}
+
+type placeHoldRequest struct {
+	ToAccountID int64     `json:"to_account_id" binding:"required,min=1"`
+	Amount      int64     `json:"amount" binding:"required,gt=0"`
+	Currency    string    `json:"currency" binding:"required,currency"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (s *Server) placeHold(ctx *gin.Context) {
+	var uri accountHoldsURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req placeHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.ToAccountID == uri.AccountID {
+		err := errors.New("cannot hold funds for the same account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	now := time.Now()
+	expiresAt := req.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(defaultHoldDuration)
+	} else if !expiresAt.After(now) || expiresAt.After(now.Add(maxHoldDuration)) {
+		err := errors.New("expires_at must be in the next 30 days")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := s.validAccount(ctx, uri.AccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canAccessAccount(authPayload, account, permTransferFrom) {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// The hold is captured without an exchange rate, so both accounts must
+	// hold the same currency.
+	if _, valid := s.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	hold, err := s.store.PlaceHold(ctx, db.PlaceHoldParams{
+		AccountID:   account.ID,
+		ToAccountID: req.ToAccountID,
+		Amount:      req.Amount,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) || errors.Is(err, db.ErrSystemAccount) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResponse(hold, account.Currency, decimalAmounts(ctx)))
+}
+
+type listHoldsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (s *Server) listAccountHolds(ctx *gin.Context) {
+	var uri accountHoldsURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listHoldsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := s.authorizedAccount(ctx, uri.AccountID, permReadAccount)
+	if !valid {
+		return
+	}
+
+	holds, err := s.store.ListAccountHolds(ctx, db.ListAccountHoldsParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	decimal := decimalAmounts(ctx)
+	rsp := make([]holdResponse, len(holds))
+	for i, hold := range holds {
+		rsp[i] = newHoldResponse(hold, account.Currency, decimal)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type holdURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type captureHoldRequest struct {
+	// Amount is captured out of the hold and the rest is released. Leave
+	// it out to capture the whole hold.
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+type captureHoldResponse struct {
+	Hold     holdResponse       `json:"hold"`
+	Transfer transferTxResponse `json:"transfer"`
+}
+
+func (s *Server) captureHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	recipient, valid := s.holdRecipient(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	result, err := s.store.CaptureHold(ctx, db.CaptureHoldParams{
+		HoldID: uri.ID,
+		Amount: req.Amount,
+	})
+	if err != nil {
+		s.holdError(ctx, err)
+		return
+	}
+
+	decimal := decimalAmounts(ctx)
+	ctx.JSON(http.StatusOK, captureHoldResponse{
+		Hold:     newHoldResponse(result.Hold, recipient.Currency, decimal),
+		Transfer: newTransferTxResponse(result.Transfer, decimal),
+	})
+}
+
+func (s *Server) releaseHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	recipient, valid := s.holdRecipient(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	hold, err := s.store.ReleaseHold(ctx, uri.ID)
+	if err != nil {
+		s.holdError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResponse(hold, recipient.Currency, decimalAmounts(ctx)))
+}
+
+// holdRecipient loads the account a hold was placed for and checks that the
+// caller may settle the hold: like a card merchant, it is the recipient who
+// captures or releases it. It writes the error response when it cannot.
+func (s *Server) holdRecipient(ctx *gin.Context, holdID int64) (db.Account, bool) {
+	hold, err := s.store.GetHold(ctx, holdID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Account{}, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Account{}, false
+	}
+
+	recipient, valid := s.loadAccount(ctx, hold.ToAccountID)
+	if !valid {
+		return recipient, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canAccessAccount(authPayload, recipient, permSettleHold) {
+		err := errors.New("hold wasn't placed for the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return recipient, false
+	}
+
+	return recipient, true
+}
+
+func (s *Server) holdError(ctx *gin.Context, err error) {
+	if errors.Is(err, db.ErrHoldNotActive) || errors.Is(err, db.ErrHoldExpired) {
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	if errors.Is(err, db.ErrCaptureExceedsHold) || errors.Is(err, db.ErrInsufficientFunds) {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
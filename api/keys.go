@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publicKeysMaxAge is how long clients may cache the published keys. A key
+// must be published at least this long before tokens are signed with it.
+const publicKeysMaxAge = 5 * time.Minute
+
+// jsonWebKey is an Ed25519 verification key in the JWK format of RFC 8037.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	Curve   string `json:"crv"`
+	Use     string `json:"use"`
+	KeyID   string `json:"kid"`
+	X       string `json:"x"`
+}
+
+type listPublicKeysResponse struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// listPublicKeys publishes the keys tokens are verified with as a JWK set,
+// so that other services can verify tokens without calling the API. The
+// set is empty when tokens are signed with a symmetric key.
+func (s *Server) listPublicKeys(ctx *gin.Context) {
+	rsp := listPublicKeysResponse{Keys: []jsonWebKey{}}
+
+	if s.keyring != nil {
+		for _, key := range s.keyring.PublicKeys() {
+			rsp.Keys = append(rsp.Keys, jsonWebKey{
+				KeyType: "OKP",
+				Curve:   "Ed25519",
+				Use:     "sig",
+				KeyID:   key.ID,
+				X:       base64.RawURLEncoding.EncodeToString(key.Key),
+			})
+		}
+	}
+
+	ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%.0f", publicKeysMaxAge.Seconds()))
+	ctx.JSON(http.StatusOK, rsp)
+}
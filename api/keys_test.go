@@ -0,0 +1,82 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListPublicKeysAPI(t *testing.T) {
+	signingKey, _, err := token.GenerateSigningKey("key-2")
+	require.NoError(t, err)
+	_, previousKey, err := token.GenerateSigningKey("key-1")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewTestServer(t, mocks.NewMockStore(ctrl))
+
+	rsp := getPublicKeys(t, server)
+	require.Empty(t, rsp.Keys)
+
+	server.config.TokenBackend = "paseto-public"
+	server.config.TokenSigningKey = signingKey
+	server.config.TokenVerificationKeys = previousKey
+	server, err = NewServer(server.config, mocks.NewMockStore(ctrl))
+	require.NoError(t, err)
+
+	rsp = getPublicKeys(t, server)
+	require.Len(t, rsp.Keys, 2)
+	require.Equal(t, "key-1", rsp.Keys[0].KeyID)
+	require.Equal(t, "key-2", rsp.Keys[1].KeyID)
+
+	// Tokens of the server verify with nothing but the published keys.
+	publicKeys := make([]token.PublicKey, len(rsp.Keys))
+	for i, key := range rsp.Keys {
+		require.Equal(t, "OKP", key.KeyType)
+		require.Equal(t, "Ed25519", key.Curve)
+
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		require.NoError(t, err)
+		publicKeys[i] = token.PublicKey{ID: key.KeyID, Key: ed25519.PublicKey(x)}
+	}
+
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	keyring, err := token.NewKeyring("other", otherKey, publicKeys)
+	require.NoError(t, err)
+	verifier, err := token.NewPasetoPublicTokenCreator(keyring, token.Options{})
+	require.NoError(t, err)
+
+	accessToken, _, err := server.tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, token.Claims{})
+	require.NoError(t, err)
+
+	_, err = verifier.VerifyToken(accessToken)
+	require.NoError(t, err)
+}
+
+func getPublicKeys(t *testing.T, server *Server) listPublicKeysResponse {
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/.well-known/keys", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "public, max-age=300", recorder.Header().Get("Cache-Control"))
+
+	var rsp listPublicKeysResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	return rsp
+}
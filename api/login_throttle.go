@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/utils"
+)
+
+// Outcomes of a login attempt, as recorded in login_attempts.
+const (
+	loginOutcomeSuccess            = "success"
+	loginOutcomeInvalidCredentials = "invalid_credentials"
+	loginOutcomeThrottled          = "throttled"
+	// loginOutcomeMFARequired is a correct password still waiting for its
+	// second factor. It does not reset the failures of the username.
+	loginOutcomeMFARequired = "mfa_required"
+)
+
+const (
+	defaultLoginMaxFailures      = 5
+	defaultLoginMaxFailuresPerIP = 20
+	defaultLoginLockoutDuration  = 15 * time.Minute
+	defaultLoginBackoff          = time.Second
+)
+
+// loginThrottle decides whether a login attempt may be checked at all,
+// from the failed attempts recorded for its username and client IP. Only
+// invalid credentials count as failures: refused attempts do not, so that
+// nobody can keep a user locked out forever.
+//
+// Concurrent attempts are counted before any of them is recorded, so a
+// burst can overshoot the limits by a few attempts.
+type loginThrottle struct {
+	maxFailures      int64
+	maxFailuresPerIP int64
+	lockout          time.Duration
+	backoff          time.Duration
+}
+
+func newLoginThrottle(config utils.Config) loginThrottle {
+	throttle := loginThrottle{
+		maxFailures:      int64(config.LoginMaxFailures),
+		maxFailuresPerIP: int64(config.LoginMaxFailuresPerIP),
+		lockout:          config.LoginLockoutDuration,
+		backoff:          config.LoginBackoff,
+	}
+
+	if throttle.maxFailures <= 0 {
+		throttle.maxFailures = defaultLoginMaxFailures
+	}
+	if throttle.maxFailuresPerIP <= 0 {
+		throttle.maxFailuresPerIP = defaultLoginMaxFailuresPerIP
+	}
+	if throttle.lockout <= 0 {
+		throttle.lockout = defaultLoginLockoutDuration
+	}
+	if throttle.backoff <= 0 {
+		throttle.backoff = defaultLoginBackoff
+	}
+
+	return throttle
+}
+
+// retryAfter returns how long the client at clientIP must wait before it
+// may try to log in as username, or zero when it may try now.
+func (t loginThrottle) retryAfter(ctx context.Context, store db.Store, username string, clientIP string, now time.Time) (time.Duration, error) {
+	since := now.Add(-t.lockout)
+
+	ipFailures, err := store.GetClientIPLoginFailures(ctx, db.GetClientIPLoginFailuresParams{
+		ClientIp: clientIP,
+		Since:    since,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if ipFailures.Failures >= t.maxFailuresPerIP {
+		return waitUntil(ipFailures.FirstFailedAt.Add(t.lockout), now), nil
+	}
+
+	userFailures, err := store.GetUsernameLoginFailures(ctx, db.GetUsernameLoginFailuresParams{
+		Username: username,
+		Since:    since,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if userFailures.Failures >= t.maxFailures {
+		return waitUntil(userFailures.FirstFailedAt.Add(t.lockout), now), nil
+	}
+
+	if userFailures.Failures > 0 {
+		return waitUntil(userFailures.LastFailedAt.Add(t.delay(userFailures.Failures)), now), nil
+	}
+
+	return 0, nil
+}
+
+// delay is the wait imposed after the given number of failures: the backoff
+// doubled for every failure after the first, and never more than the
+// lockout.
+func (t loginThrottle) delay(failures int64) time.Duration {
+	delay := t.backoff
+	for i := int64(1); i < failures && delay < t.lockout; i++ {
+		delay *= 2
+	}
+
+	if delay > t.lockout {
+		return t.lockout
+	}
+	return delay
+}
+
+func waitUntil(until time.Time, now time.Time) time.Duration {
+	if !until.After(now) {
+		return 0
+	}
+	return until.Sub(now)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestLoginThrottleDefaults(t *testing.T) {
+	throttle := newLoginThrottle(utils.Config{})
+	require.Equal(t, loginThrottle{
+		maxFailures:      defaultLoginMaxFailures,
+		maxFailuresPerIP: defaultLoginMaxFailuresPerIP,
+		lockout:          defaultLoginLockoutDuration,
+		backoff:          defaultLoginBackoff,
+	}, throttle)
+
+	throttle = newLoginThrottle(utils.Config{
+		LoginMaxFailures:      3,
+		LoginMaxFailuresPerIP: 10,
+		LoginLockoutDuration:  time.Hour,
+		LoginBackoff:          2 * time.Second,
+	})
+	require.Equal(t, int64(3), throttle.maxFailures)
+	require.Equal(t, int64(10), throttle.maxFailuresPerIP)
+	require.Equal(t, time.Hour, throttle.lockout)
+	require.Equal(t, 2*time.Second, throttle.backoff)
+}
+
+func TestLoginThrottleDelay(t *testing.T) {
+	throttle := loginThrottle{
+		maxFailures: 100,
+		lockout:     time.Minute,
+		backoff:     time.Second,
+	}
+
+	require.Equal(t, time.Second, throttle.delay(1))
+	require.Equal(t, 2*time.Second, throttle.delay(2))
+	require.Equal(t, 4*time.Second, throttle.delay(3))
+	require.Equal(t, 32*time.Second, throttle.delay(6))
+
+	// The delay never exceeds the lockout, however many failures there are.
+	require.Equal(t, time.Minute, throttle.delay(7))
+	require.Equal(t, time.Minute, throttle.delay(99))
+}
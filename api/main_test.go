@@ -1,25 +1,57 @@
 package api
 
 import (
+	"database/sql"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
 	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/exchange"
 	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
 )
 
+// testRatesUpdatedAt is when the rates used by test servers were published.
+var testRatesUpdatedAt = time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
 func NewTestServer(t *testing.T, store db.Store) *Server {
 	config := utils.Config{
-		TokenPassetoKey:     utils.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenPassetoKey:      utils.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}
+
+	// Tokens are not revoked unless a test says otherwise. This stub is
+	// registered last so expectations set in buildStubs take precedence.
+	if mockStore, ok := store.(*mocks.MockStore); ok {
+		mockStore.EXPECT().
+			IsTokenRevoked(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(false, nil)
+
+		// Likewise, users have verified their email.
+		mockStore.EXPECT().
+			IsUserEmailVerified(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(true, nil)
+
+		// And have not enabled two-factor authentication.
+		mockStore.EXPECT().
+			GetTOTPSecret(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(db.TotpSecret{}, sql.ErrNoRows)
 	}
 
 	server, err := NewServer(config, store)
 	require.NoError(t, err)
 
+	server.rates, err = exchange.NewStaticRateProvider(map[string]string{"USD/EUR": "0.9"}, testRatesUpdatedAt)
+	require.NoError(t, err)
+
 	return server
 }
 
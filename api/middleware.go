@@ -7,16 +7,21 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	db "github.com/wenealves10/gobank/db/sqlc"
 	"github.com/wenealves10/gobank/token"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
+	authorizationTypeAPIKey = "apikey"
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenCreator token.TokenCreator) gin.HandlerFunc {
+// authMiddleware authenticates the caller with a bearer token or an API
+// key. Tokens and keys that have scopes must also grant every one of
+// scopes.
+func authMiddleware(tokenCreator token.TokenCreator, revocations *revocationCache, store db.Store, scopes ...string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
@@ -32,21 +37,59 @@ func authMiddleware(tokenCreator token.TokenCreator) gin.HandlerFunc {
 			return
 		}
 
+		var payload *token.Payload
+		var status int
+		var err error
+
 		authorizationType := strings.ToLower(fields[0])
-		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
-			return
+		switch authorizationType {
+		case authorizationTypeBearer:
+			payload, status, err = verifyBearerToken(ctx, tokenCreator, revocations, fields[1])
+		case authorizationTypeAPIKey:
+			payload, status, err = verifyAPIKey(ctx, store, fields[1])
+		default:
+			status, err = http.StatusUnauthorized, fmt.Errorf("unsupported authorization type %s", authorizationType)
 		}
-
-		accessToken := fields[1]
-		payload, err := tokenCreator.VerifyToken(accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			ctx.AbortWithStatusJSON(status, errorResponse(err))
 			return
 		}
 
+		for _, scope := range scopes {
+			if !payload.HasScope(scope) {
+				err := fmt.Errorf("token does not grant scope %q", scope)
+				ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+
 		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Request = ctx.Request.WithContext(db.WithAuditActor(ctx.Request.Context(), payload.Username))
 		ctx.Next()
 	}
 }
+
+// verifyBearerToken verifies an access token that has not been revoked. It
+// returns its payload, or the status and error to answer with. Refresh
+// tokens are refused, so that a leaked one cannot be used on its own.
+func verifyBearerToken(ctx *gin.Context, tokenCreator token.TokenCreator, revocations *revocationCache, accessToken string) (*token.Payload, int, error) {
+	payload, err := tokenCreator.VerifyToken(accessToken)
+	if err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+
+	if payload.Type != token.TypeAccess {
+		return nil, http.StatusUnauthorized, errors.New("token is not an access token")
+	}
+
+	revoked, err := revocations.isRevoked(ctx, payload)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if revoked {
+		return nil, http.StatusUnauthorized, errors.New("token has been revoked")
+	}
+
+	return payload, http.StatusOK, nil
+}
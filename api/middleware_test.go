@@ -1,6 +1,7 @@
 package api
 
 import (
+	"database/sql"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -9,7 +10,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
 	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
 )
 
 func addAuthorization(
@@ -18,25 +22,45 @@ func addAuthorization(
 	tokenCreator token.TokenCreator,
 	authorizationType string,
 	username string,
+	role string,
 	duration time.Duration,
 ) {
-	token, err := tokenCreator.CreateToken(username, duration)
+	token, payload, err := tokenCreator.CreateToken(username, role, duration, token.Claims{})
 	require.NoError(t, err)
+	require.NotEmpty(t, payload)
 
 	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
 	request.Header.Set(authorizationHeaderKey, authorizationHeader)
 }
 
+// addScopedAuthorization is addAuthorization with a bearer token that only
+// grants scopes.
+func addScopedAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenCreator token.TokenCreator,
+	username string,
+	scopes ...string,
+) {
+	token, payload, err := tokenCreator.CreateToken(username, utils.DepositorRole, time.Minute, token.Claims{Scopes: scopes})
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, token)
+	request.Header.Set(authorizationHeaderKey, authorizationHeader)
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name          string
 		setupAuth     func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator)
+		buildStubs    func(store *mocks.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "user1", time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "user1", utils.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -53,7 +77,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "UnsupportedAuthorizationType",
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, "unsupported", "user1", time.Minute)
+				addAuthorization(t, request, tokenCreator, "unsupported", "user1", utils.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -62,7 +86,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "InvalidAuthorizationFormat",
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, "", "user1", time.Minute)
+				addAuthorization(t, request, tokenCreator, "", "user1", utils.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -71,22 +95,71 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "ExpiredToken",
 			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
-				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "user1", -time.Minute)
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "user1", utils.DepositorRole, -time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				refreshToken, _, err := tokenCreator.CreateToken("user1", utils.DepositorRole, time.Minute, token.Claims{Type: token.TypeRefresh})
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RevokedToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "user1", utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					IsTokenRevoked(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RevocationCheckError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "user1", utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					IsTokenRevoked(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			server := NewTestServer(t, nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+
+			server := NewTestServer(t, store)
 
 			authPath := "/auth"
-			server.router.GET(authPath, authMiddleware(server.tokenCreator), func(ctx *gin.Context) {
+			server.router.GET(authPath, authMiddleware(server.tokenCreator, server.revocations, server.store), func(ctx *gin.Context) {
 				ctx.JSON(http.StatusOK, gin.H{})
 			})
 
@@ -100,3 +173,87 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddlewareScopes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		setupAuth func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator)
+		code      int
+	}{
+		{
+			name: "Unscoped",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "user1", utils.DepositorRole, time.Minute)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "GrantsScope",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addScopedAuthorization(t, request, tokenCreator, "user1", scopeTransfersRead, scopeAccountsRead)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "MissingScope",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addScopedAuthorization(t, request, tokenCreator, "user1", scopeTransfersRead)
+			},
+			code: http.StatusForbidden,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := NewTestServer(t, mocks.NewMockStore(ctrl))
+
+			authPath := "/auth"
+			server.router.GET(authPath, authMiddleware(server.tokenCreator, server.revocations, server.store, scopeAccountsRead), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenCreator)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+		})
+	}
+}
+
+func TestReadOnlyToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	server := NewTestServer(t, store)
+
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+	require.NoError(t, err)
+
+	addScopedAuthorization(t, request, server.tokenCreator, user.Username, scopeAccountsRead, scopeTransfersRead)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodPost, "/transfers", nil)
+	require.NoError(t, err)
+
+	addScopedAuthorization(t, request, server.tokenCreator, user.Username, scopeAccountsRead, scopeTransfersRead)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
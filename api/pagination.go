@@ -0,0 +1,36 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// keysetCursor marks the last row of a page ordered by (created_at, id).
+type keysetCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+func (c keysetCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeKeysetCursor(s string) (keysetCursor, error) {
+	var c keysetCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, errInvalidCursor
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return c, errInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
+)
+
+const defaultRevocationCacheTTL = 30 * time.Second
+
+type revocationEntry struct {
+	username  string
+	issuedAt  time.Time
+	revoked   bool
+	expiresAt time.Time
+}
+
+// revocationCache remembers whether a token has been revoked so that the
+// auth middleware only asks Postgres about each token once per ttl. Tokens
+// revoked through this server are marked immediately; revocations made by
+// other replicas are picked up once the cached entry expires.
+type revocationCache struct {
+	store db.Store
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	entries   map[uuid.UUID]revocationEntry
+	lastSweep time.Time
+}
+
+func newRevocationCache(store db.Store, ttl time.Duration) *revocationCache {
+	if ttl <= 0 {
+		ttl = defaultRevocationCacheTTL
+	}
+
+	return &revocationCache{
+		store:     store,
+		ttl:       ttl,
+		entries:   make(map[uuid.UUID]revocationEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *revocationCache) isRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[payload.ID]
+	c.mu.Unlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := c.store.IsTokenRevoked(ctx, db.IsTokenRevokedParams{
+		ID:       payload.ID,
+		Username: payload.Username,
+		IssuedAt: payload.IssuedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sweep(now)
+	c.entries[payload.ID] = revocationEntry{
+		username:  payload.Username,
+		issuedAt:  payload.IssuedAt,
+		revoked:   revoked,
+		expiresAt: now.Add(c.ttl),
+	}
+
+	return revoked, nil
+}
+
+// revoke marks a single token as revoked.
+func (c *revocationCache) revoke(payload *token.Payload) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[payload.ID] = revocationEntry{
+		username:  payload.Username,
+		issuedAt:  payload.IssuedAt,
+		revoked:   true,
+		expiresAt: payload.ExpiredAt,
+	}
+}
+
+// revokeUser marks every cached token of the user issued before revokedAt
+// as revoked.
+func (c *revocationCache) revokeUser(username string, revokedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, entry := range c.entries {
+		if entry.username == username && entry.issuedAt.Before(revokedAt) {
+			entry.revoked = true
+			c.entries[id] = entry
+		}
+	}
+}
+
+// sweep drops expired entries at most once per ttl. It must be called with
+// the lock held.
+func (c *revocationCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+
+	for id, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+	c.lastSweep = now
+}
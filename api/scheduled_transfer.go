@@ -0,0 +1,270 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/scheduler"
+	"github.com/wenealves10/gobank/token"
+)
+
+type scheduledTransferResponse struct {
+	ID             int64      `json:"id"`
+	FromAccountID  int64      `json:"from_account_id"`
+	ToAccountID    int64      `json:"to_account_id"`
+	Amount         money      `json:"amount"`
+	Currency       string     `json:"currency"`
+	Frequency      string     `json:"frequency"`
+	StartAt        time.Time  `json:"start_at"`
+	NextRunAt      time.Time  `json:"next_run_at"`
+	Status         string     `json:"status"`
+	Runs           int32      `json:"runs"`
+	Attempts       int32      `json:"attempts"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastTransferID *int64     `json:"last_transfer_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func newScheduledTransferResponse(schedule db.ScheduledTransfer, decimal bool) scheduledTransferResponse {
+	rsp := scheduledTransferResponse{
+		ID:            schedule.ID,
+		FromAccountID: schedule.FromAccountID,
+		ToAccountID:   schedule.ToAccountID,
+		Amount:        newMoney(schedule.Amount, schedule.Currency, decimal),
+		Currency:      schedule.Currency,
+		Frequency:     schedule.Frequency,
+		StartAt:       schedule.StartAt,
+		NextRunAt:     schedule.NextRunAt,
+		Status:        schedule.Status,
+		Runs:          schedule.Runs,
+		Attempts:      schedule.Attempts,
+		LastError:     schedule.LastError,
+		CreatedAt:     schedule.CreatedAt,
+	}
+
+	if schedule.LastRunAt.Valid {
+		rsp.LastRunAt = &schedule.LastRunAt.Time
+	}
+
+	if schedule.LastTransferID.Valid {
+		rsp.LastTransferID = &schedule.LastTransferID.Int64
+	}
+
+	return rsp
+}
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64     `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64     `json:"amount" binding:"required,gt=0"`
+	Currency      string    `json:"currency" binding:"required,currency"`
+	Frequency     string    `json:"frequency" binding:"required,oneof=once daily weekly monthly"`
+	StartAt       time.Time `json:"start_at"`
+}
+
+func (s *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	startAt := req.StartAt
+	if startAt.IsZero() {
+		startAt = time.Now()
+	} else if startAt.Before(time.Now().Add(-time.Minute)) {
+		err := errors.New("start_at must not be in the past")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := s.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canAccessAccount(authPayload, fromAccount, permTransferFrom) {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// Scheduled transfers have no exchange rate to run at, so both
+	// accounts must hold the same currency.
+	if _, valid := s.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	schedule, err := s.store.CreateScheduledTransfer(ctx, db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Frequency:     req.Frequency,
+		StartAt:       startAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(schedule, decimalAmounts(ctx)))
+}
+
+type scheduledTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	schedule, valid := s.ownedScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(schedule, decimalAmounts(ctx)))
+}
+
+type listScheduledTransfersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (s *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	schedules, err := s.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]scheduledTransferResponse, len(schedules))
+	for i, schedule := range schedules {
+		rsp[i] = newScheduledTransferResponse(schedule, decimalAmounts(ctx))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type updateScheduledTransferRequest struct {
+	Amount *int64  `json:"amount" binding:"omitempty,gt=0"`
+	Status *string `json:"status" binding:"omitempty,oneof=active paused"`
+}
+
+func (s *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	schedule, valid := s.ownedScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if schedule.Status == scheduler.StatusCompleted {
+		err := errors.New("scheduled transfer is already completed")
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	arg := db.UpdateScheduledTransferParams{ID: schedule.ID}
+
+	if req.Amount != nil {
+		arg.Amount = sql.NullInt64{Int64: *req.Amount, Valid: true}
+	}
+
+	if req.Status != nil {
+		arg.Status = sql.NullString{String: *req.Status, Valid: true}
+
+		// A resumed schedule carries on from its next run instead of
+		// paying the runs it missed.
+		now := time.Now()
+		if *req.Status == scheduler.StatusActive && schedule.NextRunAt.Before(now) {
+			if next, ok := scheduler.NextRunAt(schedule.Frequency, schedule.StartAt, now); ok {
+				arg.NextRunAt = sql.NullTime{Time: next, Valid: true}
+			}
+		}
+	}
+
+	schedule, err := s.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(schedule, decimalAmounts(ctx)))
+}
+
+func (s *Server) deleteScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	schedule, valid := s.ownedScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if err := s.store.DeleteScheduledTransfer(ctx, schedule.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ownedScheduledTransfer loads a scheduled transfer of the authenticated
+// user, writing the error response when it cannot.
+func (s *Server) ownedScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	schedule, err := s.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return schedule, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return schedule, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if schedule.Owner != authPayload.Username {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return schedule, false
+	}
+
+	return schedule, true
+}
@@ -2,11 +2,14 @@ package api
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/exchange"
+	"github.com/wenealves10/gobank/mail"
 	"github.com/wenealves10/gobank/token"
 	"github.com/wenealves10/gobank/utils"
 )
@@ -15,24 +18,42 @@ type Server struct {
 	config       utils.Config
 	store        db.Store
 	tokenCreator token.TokenCreator
-	router       *gin.Engine
+	// keyring holds the public keys tokens are verified with, or is nil
+	// when tokens are signed with a symmetric key.
+	keyring     *token.Keyring
+	revocations *revocationCache
+	logins      loginThrottle
+	rates       exchange.ExchangeRateProvider
+	mailer      mail.Mailer
+	router      *gin.Engine
 }
 
 func NewServer(config utils.Config, store db.Store) (*Server, error) {
 
-	tokenCreator, err := token.NewPasetoTokenCreator(config.TokenPassetoKey)
+	tokenCreator, keyring, err := newTokenCreator(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token creator: %w", err)
 	}
 
+	rates, err := newRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create exchange rate provider: %w", err)
+	}
+
 	server := &Server{
 		store:        store,
 		tokenCreator: tokenCreator,
+		keyring:      keyring,
+		revocations:  newRevocationCache(store, config.TokenRevocationCacheTTL),
+		logins:       newLoginThrottle(config),
+		rates:        rates,
+		mailer:       newMailer(config),
 		config:       config,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("scope", validScope)
 	}
 
 	server.setupRouter()
@@ -41,19 +62,157 @@ func NewServer(config utils.Config, store db.Store) (*Server, error) {
 
 func (server *Server) setupRouter() {
 	router := gin.Default()
+	// Store calls are made with the gin context, which must reach the
+	// audit context set on the request.
+	router.ContextWithFallback = true
+	router.Use(auditMiddleware())
 
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
+	router.POST("/users/login/totp", server.loginTOTP)
+	router.POST("/users/verify_email", server.verifyEmail)
+	router.POST("/users/password_reset", server.requestPasswordReset)
+	router.POST("/users/password_reset/confirm", server.resetPassword)
+	router.POST("/tokens/renew_access", server.renewAccessToken)
+	router.GET("/currencies", server.listCurrencies)
+	router.GET("/.well-known/keys", server.listPublicKeys)
+
+	// Every group of routes requires a scope of the tokens that have
+	// scopes, so that e.g. a read-only token can list accounts but cannot
+	// move money.
+	auth := func(scopes ...string) gin.IRoutes {
+		return router.Group("/").Use(authMiddleware(server.tokenCreator, server.revocations, server.store, scopes...))
+	}
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenCreator))
+	// Tokens and keys with scopes can never have scopeAPIKeys, so only a
+	// logged in user manages their API keys.
+	apiKeyRoutes := auth(scopeAPIKeys)
+	apiKeyRoutes.POST("/users/me/api_keys", server.createAPIKey)
+	apiKeyRoutes.GET("/users/me/api_keys", server.listAPIKeys)
+	apiKeyRoutes.DELETE("/users/me/api_keys/:id", server.revokeAPIKey)
+	apiKeyRoutes.GET("/users/me/api_keys/:id/usages", server.listAPIKeyUsages)
+
+	profileRoutes := auth(scopeProfile)
+	profileRoutes.POST("/users/logout", server.logoutUser)
+	profileRoutes.POST("/users/logout_all", server.logoutAllDevices)
+	profileRoutes.GET("/users/me", server.getCurrentUser)
+	profileRoutes.PATCH("/users/me", server.updateCurrentUser)
+	profileRoutes.PUT("/users/me/password", server.changePassword)
+	profileRoutes.POST("/users/verify_email/resend", server.resendVerifyEmail)
+	profileRoutes.POST("/users/me/totp", server.enrollTOTP)
+	profileRoutes.POST("/users/me/totp/confirm", server.confirmTOTP)
+
+	accountReadRoutes := auth(scopeAccountsRead)
+	accountReadRoutes.GET("/accounts/:id", server.getAccount)
+	accountReadRoutes.GET("/accounts", server.listAccount)
+	accountReadRoutes.GET("/accounts/:id/entries", server.listEntries)
+	accountReadRoutes.GET("/accounts/:id/transfers", server.listAccountTransfers)
+	accountReadRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
+	accountReadRoutes.GET("/accounts/:id/holds", server.listAccountHolds)
+
+	accountWriteRoutes := auth(scopeAccountsWrite)
+	accountWriteRoutes.POST("/accounts", server.createAccount)
+	accountWriteRoutes.PUT("/accounts/:id/freeze", requirePermission(permFreezeAccount), server.freezeAccount)
+	accountWriteRoutes.DELETE("/accounts/:id/freeze", requirePermission(permFreezeAccount), server.unfreezeAccount)
+	accountWriteRoutes.POST("/accounts/:id/close", server.closeAccount)
+	accountWriteRoutes.POST("/accounts/:id/reopen", server.reopenAccount)
+	accountWriteRoutes.POST("/accounts/:id/deposits", requirePermission(permMoveCash), server.depositAccount)
+	accountWriteRoutes.POST("/accounts/:id/withdrawals", requirePermission(permMoveCash), server.withdrawAccount)
+
+	transferReadRoutes := auth(scopeTransfersRead)
+	transferReadRoutes.GET("/transfers", server.listTransfers)
+	transferReadRoutes.GET("/transfers/:id", server.getTransfer)
+	transferReadRoutes.GET("/scheduled-transfers", server.listScheduledTransfers)
+	transferReadRoutes.GET("/scheduled-transfers/:id", server.getScheduledTransfer)
+
+	transferWriteRoutes := auth(scopeTransfersWrite)
+	transferWriteRoutes.POST("/transfers", requireVerifiedEmail(server.store), server.createTransfer)
+	transferWriteRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+	transferWriteRoutes.POST("/accounts/:id/holds", requireVerifiedEmail(server.store), server.placeHold)
+	transferWriteRoutes.POST("/holds/:id/capture", server.captureHold)
+	transferWriteRoutes.POST("/holds/:id/release", server.releaseHold)
+	transferWriteRoutes.POST("/scheduled-transfers", requireVerifiedEmail(server.store), server.createScheduledTransfer)
+	transferWriteRoutes.PATCH("/scheduled-transfers/:id", server.updateScheduledTransfer)
+	transferWriteRoutes.DELETE("/scheduled-transfers/:id", server.deleteScheduledTransfer)
+
+	auditRoutes := auth(scopeAuditRead)
+	auditRoutes.GET("/audit_events", requirePermission(permReadAuditLog), server.listAuditEvents)
+	auditRoutes.GET("/audit_events/verify", requirePermission(permReadAuditLog), server.verifyAuditEvents)
 
-	authRoutes.POST("/accounts", server.createAccount)
-	authRoutes.GET("/accounts/:id", server.getAccount)
-	authRoutes.GET("/accounts", server.listAccount)
+	server.router = router
+}
 
-	authRoutes.POST("/transfers", server.createTransfer)
+// newTokenCreator creates the token creator of the configured backend,
+// which also verifies the tokens of the previous backend if there is one.
+// The keyring is returned when a backend signs with it, so that its keys
+// are published.
+func newTokenCreator(config utils.Config) (token.TokenCreator, *token.Keyring, error) {
+	var keyring *token.Keyring
+	if usesKeyring(config.TokenBackend) || usesKeyring(config.TokenPreviousBackend) {
+		var err error
+		keyring, err = token.ParseKeyring(config.TokenSigningKey, config.TokenVerificationKeys)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
-	server.router = router
+	tokenCreator, err := newTokenBackend(config.TokenBackend, config, keyring)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if config.TokenPreviousBackend == "" {
+		return tokenCreator, keyring, nil
+	}
+
+	previous, err := newTokenBackend(config.TokenPreviousBackend, config, keyring)
+	if err != nil {
+		return nil, nil, fmt.Errorf("previous backend: %w", err)
+	}
+
+	return token.NewDualTokenCreator(tokenCreator, previous), keyring, nil
+}
+
+func newTokenBackend(backend string, config utils.Config, keyring *token.Keyring) (token.TokenCreator, error) {
+	options := token.Options{
+		Issuer:   config.TokenIssuer,
+		Audience: config.TokenAudience,
+	}
+
+	switch backend {
+	case "", "paseto-local":
+		return token.NewPasetoTokenCreator(config.TokenPassetoKey, options)
+	case "paseto-public":
+		return token.NewPasetoPublicTokenCreator(keyring, options)
+	case "jwt-hs256":
+		return token.NewJWTTokenCreator(config.TokenJWTSecret, options)
+	case "jwt-eddsa":
+		return token.NewJWTEdDSATokenCreator(keyring, options)
+	default:
+		return nil, fmt.Errorf("unknown token backend %q", backend)
+	}
+}
+
+func usesKeyring(backend string) bool {
+	return backend == "paseto-public" || backend == "jwt-eddsa"
+}
+
+func newRateProvider(config utils.Config) (exchange.ExchangeRateProvider, error) {
+	if config.ExchangeRatesFile != "" {
+		return exchange.NewFileRateProvider(config.ExchangeRatesFile)
+	}
+	return exchange.NewStaticRateProvider(nil, time.Now())
+}
+
+func newMailer(config utils.Config) mail.Mailer {
+	switch {
+	case config.SMTPHost != "":
+		return mail.NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+	case config.MailFile != "":
+		return mail.NewFileMailer(config.MailFile, config.MailFrom)
+	default:
+		return mail.NewMemoryMailer()
+	}
 }
 
 func (s *Server) Start(address string) error {
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func testTokenConfig(t *testing.T, backend string, previousBackend string) utils.Config {
+	signingKey, _, err := token.GenerateSigningKey("key-1")
+	require.NoError(t, err)
+
+	return utils.Config{
+		TokenBackend:         backend,
+		TokenPreviousBackend: previousBackend,
+		TokenPassetoKey:      utils.RandomString(32),
+		TokenJWTSecret:       utils.RandomString(32),
+		TokenSigningKey:      signingKey,
+		TokenIssuer:          "gobank",
+		TokenAudience:        "gobank",
+	}
+}
+
+func TestNewTokenCreator(t *testing.T) {
+	testCases := []struct {
+		backend     string
+		usesKeyring bool
+	}{
+		{"", false},
+		{"paseto-local", false},
+		{"paseto-public", true},
+		{"jwt-hs256", false},
+		{"jwt-eddsa", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.backend, func(t *testing.T) {
+			tokenCreator, keyring, err := newTokenCreator(testTokenConfig(t, tc.backend, ""))
+			require.NoError(t, err)
+			require.Equal(t, tc.usesKeyring, keyring != nil)
+
+			accessToken, _, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, token.Claims{})
+			require.NoError(t, err)
+
+			_, err = tokenCreator.VerifyToken(accessToken)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewTokenCreatorMigration(t *testing.T) {
+	config := testTokenConfig(t, "paseto-local", "")
+	previous, _, err := newTokenCreator(config)
+	require.NoError(t, err)
+
+	previousToken, _, err := previous.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, token.Claims{})
+	require.NoError(t, err)
+
+	// During the migration window tokens of the previous backend are still
+	// accepted, while new ones are created in the new format.
+	config.TokenBackend, config.TokenPreviousBackend = "jwt-eddsa", "paseto-local"
+	tokenCreator, keyring, err := newTokenCreator(config)
+	require.NoError(t, err)
+	require.NotNil(t, keyring)
+
+	_, err = tokenCreator.VerifyToken(previousToken)
+	require.NoError(t, err)
+
+	accessToken, _, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, token.Claims{})
+	require.NoError(t, err)
+
+	_, err = previous.VerifyToken(accessToken)
+	require.Error(t, err)
+
+	// Once the window is over, they are not.
+	config.TokenPreviousBackend = ""
+	tokenCreator, _, err = newTokenCreator(config)
+	require.NoError(t, err)
+
+	_, err = tokenCreator.VerifyToken(previousToken)
+	require.ErrorIs(t, err, token.ErrInvalidToken)
+}
+
+func TestNewTokenCreatorInvalidConfig(t *testing.T) {
+	config := testTokenConfig(t, "jwt-rs256", "")
+	_, _, err := newTokenCreator(config)
+	require.Error(t, err)
+
+	config = testTokenConfig(t, "jwt-hs256", "paseto-v1")
+	_, _, err = newTokenCreator(config)
+	require.Error(t, err)
+
+	config = testTokenConfig(t, "jwt-eddsa", "")
+	config.TokenSigningKey = ""
+	_, _, err = newTokenCreator(config)
+	require.Error(t, err)
+
+	config = testTokenConfig(t, "jwt-hs256", "")
+	config.TokenJWTSecret = "short"
+	_, _, err = newTokenCreator(config)
+	require.Error(t, err)
+}
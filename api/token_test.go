@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	username := utils.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		buildBody     func(t *testing.T, refreshToken string) gin.H
+		buildStubs    func(store *mocks.MockStore, refreshToken string, payload *token.Payload)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				session := db.Session{
+					ID:           payload.ID,
+					Username:     username,
+					RefreshToken: refreshToken,
+					ExpiresAt:    payload.ExpiredAt,
+				}
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{Username: username, Role: utils.DepositorRole}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp renewAccessTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.AccessToken)
+				require.WithinDuration(t, time.Now().Add(time.Minute), rsp.AccessTokenExpiresAt, time.Second)
+			},
+		},
+		{
+			name: "InvalidToken",
+			buildBody: func(t *testing.T, refreshToken string) gin.H {
+				return gin.H{"refresh_token": "invalid-token"}
+			},
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MissingToken",
+			buildBody: func(t *testing.T, refreshToken string) gin.H {
+				return gin.H{}
+			},
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidScope",
+			buildBody: func(t *testing.T, refreshToken string) gin.H {
+				return gin.H{"refresh_token": refreshToken, "scopes": []string{"accounts:delete"}}
+			},
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.ID)).Times(1).Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				session := db.Session{
+					ID:           payload.ID,
+					Username:     username,
+					RefreshToken: refreshToken,
+					IsBlocked:    true,
+					ExpiresAt:    payload.ExpiredAt,
+				}
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MismatchedSessionToken",
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				session := db.Session{
+					ID:           payload.ID,
+					Username:     username,
+					RefreshToken: "another-token",
+					ExpiresAt:    payload.ExpiredAt,
+				}
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredSession",
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				session := db.Session{
+					ID:           payload.ID,
+					Username:     username,
+					RefreshToken: refreshToken,
+					ExpiresAt:    time.Now().Add(-time.Minute),
+				}
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				session := db.Session{
+					ID:           payload.ID,
+					Username:     username,
+					RefreshToken: refreshToken,
+					ExpiresAt:    payload.ExpiredAt,
+				}
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(payload.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mocks.MockStore, refreshToken string, payload *token.Payload) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			server := NewTestServer(t, store)
+
+			refreshToken, payload, err := server.tokenCreator.CreateToken(username, utils.DepositorRole, time.Hour, token.Claims{Type: token.TypeRefresh})
+			require.NoError(t, err)
+			tc.buildStubs(store, refreshToken, payload)
+
+			body := gin.H{"refresh_token": refreshToken}
+			if tc.buildBody != nil {
+				body = tc.buildBody(t, refreshToken)
+			}
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			url := "/tokens/renew_access"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRenewScopedAccessTokenAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	server := NewTestServer(t, store)
+
+	username := utils.RandomOwner()
+	refreshToken, refreshPayload, err := server.tokenCreator.CreateToken(username, utils.DepositorRole, time.Hour, token.Claims{Type: token.TypeRefresh})
+	require.NoError(t, err)
+
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(refreshPayload.ID)).
+		Times(1).
+		Return(db.Session{
+			ID:           refreshPayload.ID,
+			Username:     username,
+			RefreshToken: refreshToken,
+			ExpiresAt:    refreshPayload.ExpiredAt,
+		}, nil)
+	// The user was made a banker after logging in.
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(db.User{Username: username, Role: utils.BankerRole}, nil)
+
+	scopes := []string{scopeAccountsRead, scopeTransfersRead}
+	data, err := json.Marshal(gin.H{"refresh_token": refreshToken, "scopes": scopes})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp renewAccessTokenResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+
+	accessPayload, err := server.tokenCreator.VerifyToken(rsp.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, scopes, accessPayload.Scopes)
+	require.Equal(t, refreshPayload.ID, accessPayload.SessionID)
+	require.Equal(t, utils.BankerRole, accessPayload.Role)
+}
+
+func TestRenewAccessTokenWithAccessTokenAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+	server := NewTestServer(t, store)
+
+	// An access token cannot be renewed into a fresh one.
+	accessToken, _, err := server.tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, token.Claims{})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(gin.H{"refresh_token": accessToken})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
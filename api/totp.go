@@ -0,0 +1,325 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/totp"
+	"github.com/wenealves10/gobank/utils"
+)
+
+const (
+	// totpIssuer names the bank in authenticator apps.
+	totpIssuer = "GoBank"
+	// mfaChallengeDuration is how long a login has to provide its second
+	// factor once the password was accepted.
+	mfaChallengeDuration = 5 * time.Minute
+	// maxMFAChallengeAttempts is how many codes may be tried against one
+	// challenge.
+	maxMFAChallengeAttempts = 5
+	// recoveryCodeCount is how many recovery codes a user gets when
+	// enabling two-factor authentication.
+	recoveryCodeCount = 10
+)
+
+var (
+	errTOTPEnabled      = errors.New("two-factor authentication is already enabled")
+	errTOTPNotEnrolled  = errors.New("two-factor authentication is not enrolled")
+	errInvalidTOTPCode  = errors.New("invalid two-factor authentication code")
+	errInvalidChallenge = errors.New("login challenge is invalid, expired or already used")
+)
+
+type enrollTOTPResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// enrollTOTP gives the authenticated user a new TOTP secret to add to their
+// authenticator app. Two-factor authentication is only enabled once a code
+// generated from it is confirmed.
+func (s *Server) enrollTOTP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = s.store.UpsertTOTPSecret(ctx, db.UpsertTOTPSecretParams{
+		Username: authPayload.Username,
+		Secret:   secret,
+	})
+	if err != nil {
+		// The secret is only replaced while it is unconfirmed.
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(errTOTPEnabled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollTOTPResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(totpIssuer, authPayload.Username, secret),
+	})
+}
+
+type confirmTOTPRequest struct {
+	Code string `json:"code" binding:"required,numeric,len=6"`
+}
+
+type confirmTOTPResponse struct {
+	// RecoveryCodes are shown once. Each of them logs in once in place of
+	// a TOTP code.
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTP enables two-factor authentication once the user proves their
+// authenticator app generates the right codes.
+func (s *Server) confirmTOTP(ctx *gin.Context) {
+	var req confirmTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	secret, err := s.store.GetTOTPSecret(ctx, authPayload.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errTOTPNotEnrolled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if secret.ConfirmedAt.Valid {
+		ctx.JSON(http.StatusConflict, errorResponse(errTOTPEnabled))
+		return
+	}
+
+	step, ok := totp.Validate(secret.Secret, req.Code, time.Now())
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidTOTPCode))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = s.store.ConfirmTOTPTx(ctx, db.ConfirmTOTPTxParams{
+		Username:           authPayload.Username,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCode) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidTOTPCode))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTOTPResponse{RecoveryCodes: codes})
+}
+
+type loginChallengeResponse struct {
+	MFARequired        bool      `json:"mfa_required"`
+	ChallengeToken     string    `json:"challenge_token"`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at"`
+}
+
+// startMFAChallenge answers a correct password of a user with two-factor
+// authentication by a challenge token, which loginTOTP exchanges for the
+// session once it comes back with a valid code.
+func (s *Server) startMFAChallenge(ctx *gin.Context, user db.User) {
+	if err := s.recordLoginAttempt(ctx, user.Username, loginOutcomeMFARequired); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	challengeToken, err := utils.NewSecretCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	challenge, err := s.store.CreateMFAChallenge(ctx, db.CreateMFAChallengeParams{
+		Username:  user.Username,
+		TokenHash: utils.HashSecretCode(challengeToken),
+		ExpiresAt: time.Now().Add(mfaChallengeDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginChallengeResponse{
+		MFARequired:        true,
+		ChallengeToken:     challengeToken,
+		ChallengeExpiresAt: challenge.ExpiresAt,
+	})
+}
+
+type loginTOTPRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code is either a TOTP code or an unused recovery code.
+	Code string `json:"code" binding:"required"`
+}
+
+func (s *Server) loginTOTP(ctx *gin.Context) {
+	var req loginTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	challenge, err := s.store.ClaimMFAChallengeAttempt(ctx, db.ClaimMFAChallengeAttemptParams{
+		TokenHash:   utils.HashSecretCode(req.ChallengeToken),
+		MaxAttempts: maxMFAChallengeAttempts,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidChallenge))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !s.allowLoginAttempt(ctx, challenge.Username) {
+		return
+	}
+
+	valid, err := s.checkSecondFactor(ctx, challenge.Username, req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !valid {
+		if err := s.recordLoginAttempt(ctx, challenge.Username, loginOutcomeInvalidCredentials); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidTOTPCode))
+		return
+	}
+
+	rows, err := s.store.UseMFAChallenge(ctx, challenge.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if rows == 0 {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidChallenge))
+		return
+	}
+
+	user, err := s.store.GetUser(ctx, challenge.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := s.recordLoginAttempt(ctx, user.Username, loginOutcomeSuccess); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	s.startSession(ctx, user)
+}
+
+// checkSecondFactor checks code as a TOTP code of the user, or failing that
+// as one of their recovery codes. A valid code is used up, so that it cannot
+// log anyone in again.
+func (s *Server) checkSecondFactor(ctx *gin.Context, username string, code string) (bool, error) {
+	secret, err := s.store.GetTOTPSecret(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !secret.ConfirmedAt.Valid {
+		return false, nil
+	}
+
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(secret.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		rows, err := s.store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+			Step:     step,
+			Username: username,
+		})
+		return rows == 1, err
+	}
+
+	recoveryCodes, err := s.store.ListUnusedRecoveryCodes(ctx, username)
+	if err != nil {
+		return false, err
+	}
+
+	code = normalizeRecoveryCode(code)
+	for _, recoveryCode := range recoveryCodes {
+		if utils.CheckPassword(code, recoveryCode.CodeHash) != nil {
+			continue
+		}
+
+		rows, err := s.store.UseRecoveryCode(ctx, recoveryCode.ID)
+		return rows == 1, err
+	}
+
+	return false, nil
+}
+
+// newRecoveryCodes returns a set of recovery codes, formatted for the user
+// to write down, and the bcrypt hashes they are stored as.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		code, err := utils.NewRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = code[:5] + "-" + code[5:]
+
+		hash, err := utils.HashPassword(code)
+		if err != nil {
+			return nil, nil, err
+		}
+		hashes[i] = hash
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode accepts recovery codes however the user typed them
+// back, with or without the dash and in any case.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return strings.ToLower(code)
+}
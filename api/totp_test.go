@@ -0,0 +1,664 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/totp"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func randomTOTPSecret(t *testing.T, username string, confirmed bool) db.TotpSecret {
+	secret, err := totp.NewSecret()
+	require.NoError(t, err)
+
+	totpSecret := db.TotpSecret{
+		Username:  username,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+	if confirmed {
+		totpSecret.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	return totpSecret
+}
+
+func currentTOTPCode(t *testing.T, secret db.TotpSecret) string {
+	code, err := totp.Code(secret.Secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+func TestEnrollTOTPAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, server *Server)
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpsertTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpsertTOTPSecretParams) (db.TotpSecret, error) {
+						require.Equal(t, user.Username, arg.Username)
+						return db.TotpSecret{Username: arg.Username, Secret: arg.Secret}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp enrollTOTPResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.Secret)
+				require.Equal(t, totp.ProvisioningURI(totpIssuer, user.Username, rsp.Secret), rsp.ProvisioningURI)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpsertTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpsertTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpsertTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/totp", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestConfirmTOTPAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	pending := randomTOTPSecret(t, user.Username, false)
+
+	testCases := []struct {
+		name          string
+		body          func() gin.H
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func() gin.H {
+				return gin.H{"code": currentTOTPCode(t, pending)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ConfirmTOTPTxParams) (db.TotpSecret, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, totp.Step(time.Now()), arg.Step, totp.Skew)
+						require.Len(t, arg.RecoveryCodeHashes, recoveryCodeCount)
+
+						confirmed := pending
+						confirmed.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+						confirmed.LastUsedStep = arg.Step
+						return confirmed, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp confirmTOTPResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.RecoveryCodes, recoveryCodeCount)
+				for _, code := range rsp.RecoveryCodes {
+					require.Regexp(t, "^[a-z2-7]{5}-[a-z2-7]{5}$", code)
+				}
+			},
+		},
+		{
+			name: "NotEnrolled",
+			body: func() gin.H {
+				return gin.H{"code": "123456"}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpSecret{}, sql.ErrNoRows)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			body: func() gin.H {
+				return gin.H{"code": currentTOTPCode(t, pending)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				confirmed := pending
+				confirmed.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(confirmed, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: func() gin.H {
+				other := randomTOTPSecret(t, user.Username, false)
+				return gin.H{"code": currentTOTPCode(t, other)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "CodeAlreadyUsed",
+			body: func() gin.H {
+				return gin.H{"code": currentTOTPCode(t, pending)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TotpSecret{}, db.ErrInvalidCode)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRequest",
+			body: func() gin.H {
+				return gin.H{"code": "abc"}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body())
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/totp/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, user.Role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestLoginUserRequiresTOTP(t *testing.T) {
+	user, password := randomUser(t)
+	secret := randomTOTPSecret(t, user.Username, true)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	allowLogin(store)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(secret, nil)
+	expectLoginAttempt(store, user.Username, loginOutcomeMFARequired)
+
+	var tokenHash string
+	store.EXPECT().
+		CreateMFAChallenge(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateMFAChallengeParams) (db.MfaChallenge, error) {
+			require.Equal(t, user.Username, arg.Username)
+			require.WithinDuration(t, time.Now().Add(mfaChallengeDuration), arg.ExpiresAt, time.Second)
+			tokenHash = arg.TokenHash
+			return db.MfaChallenge{ID: 1, Username: arg.Username, TokenHash: arg.TokenHash, ExpiresAt: arg.ExpiresAt}, nil
+		})
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"username": user.Username, "password": password})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rsp loginChallengeResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.True(t, rsp.MFARequired)
+	require.Equal(t, tokenHash, utils.HashSecretCode(rsp.ChallengeToken))
+
+	// No tokens are handed out before the second factor.
+	require.NotContains(t, recorder.Body.String(), "access_token")
+}
+
+func TestLoginTOTPAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	secret := randomTOTPSecret(t, user.Username, true)
+
+	challengeToken, err := utils.NewSecretCode()
+	require.NoError(t, err)
+	challenge := db.MfaChallenge{
+		ID:        utils.RandomInt(1, 1000),
+		Username:  user.Username,
+		TokenHash: utils.HashSecretCode(challengeToken),
+		Attempts:  1,
+		ExpiresAt: time.Now().Add(mfaChallengeDuration),
+	}
+
+	recoveryCode, err := utils.NewRecoveryCode()
+	require.NoError(t, err)
+	recoveryCodeHash, err := utils.HashPassword(recoveryCode)
+	require.NoError(t, err)
+
+	claimChallenge := func(store *mocks.MockStore) {
+		store.EXPECT().
+			ClaimMFAChallengeAttempt(gomock.Any(), gomock.Eq(db.ClaimMFAChallengeAttemptParams{
+				TokenHash:   challenge.TokenHash,
+				MaxAttempts: maxMFAChallengeAttempts,
+			})).
+			Times(1).
+			Return(challenge, nil)
+	}
+
+	logIn := func(store *mocks.MockStore) {
+		store.EXPECT().
+			UseMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+			Times(1).
+			Return(int64(1), nil)
+		store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(user.Username)).
+			Times(1).
+			Return(user, nil)
+		expectLoginAttempt(store, user.Username, loginOutcomeSuccess)
+		store.EXPECT().
+			CreateSession(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+				return db.Session{ID: arg.ID, Username: arg.Username}, nil
+			})
+	}
+
+	refuse := func(store *mocks.MockStore) {
+		expectLoginAttempt(store, user.Username, loginOutcomeInvalidCredentials)
+		store.EXPECT().
+			UseMFAChallenge(gomock.Any(), gomock.Any()).
+			Times(0)
+		store.EXPECT().
+			CreateSession(gomock.Any(), gomock.Any()).
+			Times(0)
+	}
+
+	testCases := []struct {
+		name          string
+		body          func() gin.H
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func() gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				claimChallenge(store)
+				allowLogin(store)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(secret, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UseTOTPStepParams) (int64, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, totp.Step(time.Now()), arg.Step, totp.Skew)
+						return 1, nil
+					})
+				logIn(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.AccessToken)
+				require.NotEmpty(t, rsp.RefreshToken)
+				require.Equal(t, user.Username, rsp.User.Username)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: func() gin.H {
+				typed := strings.ToUpper(recoveryCode[:5] + "-" + recoveryCode[5:])
+				return gin.H{"challenge_token": challengeToken, "code": typed}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				claimChallenge(store)
+				allowLogin(store)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(secret, nil)
+				store.EXPECT().
+					ListUnusedRecoveryCodes(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return([]db.RecoveryCode{{ID: 7, Username: user.Username, CodeHash: recoveryCodeHash}}, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Eq(int64(7))).
+					Times(1).
+					Return(int64(1), nil)
+				logIn(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidChallenge",
+			body: func() gin.H {
+				return gin.H{"challenge_token": "unknown", "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					ClaimMFAChallengeAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetUsernameLoginFailures(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			body: func() gin.H {
+				other := randomTOTPSecret(t, user.Username, true)
+				return gin.H{"challenge_token": challengeToken, "code": currentTOTPCode(t, other)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				claimChallenge(store)
+				allowLogin(store)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(secret, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				refuse(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "CodeAlreadyUsed",
+			body: func() gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				claimChallenge(store)
+				allowLogin(store)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(secret, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				refuse(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RecoveryCodeAlreadyUsed",
+			body: func() gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": recoveryCode}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				claimChallenge(store)
+				allowLogin(store)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(secret, nil)
+				store.EXPECT().
+					ListUnusedRecoveryCodes(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return([]db.RecoveryCode{}, nil)
+				refuse(store)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ChallengeAlreadyUsed",
+			body: func() gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				claimChallenge(store)
+				allowLogin(store)
+				store.EXPECT().
+					GetTOTPSecret(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(secret, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					UseMFAChallenge(gomock.Any(), gomock.Eq(challenge.ID)).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Throttled",
+			body: func() gin.H {
+				return gin.H{"challenge_token": challengeToken, "code": currentTOTPCode(t, secret)}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				claimChallenge(store)
+				store.EXPECT().
+					GetClientIPLoginFailures(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetClientIPLoginFailuresRow{}, nil)
+				store.EXPECT().
+					GetUsernameLoginFailures(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetUsernameLoginFailuresRow{
+						Failures:      defaultLoginMaxFailures,
+						FirstFailedAt: time.Now(),
+						LastFailedAt:  time.Now(),
+					}, nil)
+				expectLoginAttempt(store, user.Username, loginOutcomeThrottled)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireRetryAfter(t, recorder, defaultLoginLockoutDuration)
+			},
+		},
+		{
+			name: "InvalidRequest",
+			body: func() gin.H {
+				return gin.H{"challenge_token": challengeToken}
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					ClaimMFAChallengeAttempt(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body())
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login/totp", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -1,16 +1,124 @@
 package api
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/exchange"
 	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
 )
 
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+type transferResponse struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        money     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	FromCurrency  string    `json:"from_currency"`
+	ToAmount      money     `json:"to_amount"`
+	ToCurrency    string    `json:"to_currency"`
+	ExchangeRate  string    `json:"exchange_rate"`
+	RateUpdatedAt time.Time `json:"rate_updated_at"`
+	// ReversalOf is set on reversals to the transfer they refund.
+	ReversalOf     *int64 `json:"reversal_of,omitempty"`
+	ReversedAmount money  `json:"reversed_amount"`
+	ReversalStatus string `json:"reversal_status"`
+}
+
+// How much of a transfer has been refunded by reversals.
+const (
+	reversalStatusNone     = "not_reversed"
+	reversalStatusPartial  = "partially_reversed"
+	reversalStatusReversed = "reversed"
+)
+
+func newTransferResponse(transfer db.Transfer, decimal bool) transferResponse {
+	rsp := transferResponse{
+		ID:             transfer.ID,
+		FromAccountID:  transfer.FromAccountID,
+		ToAccountID:    transfer.ToAccountID,
+		Amount:         newMoney(transfer.Amount, transfer.FromCurrency, decimal),
+		CreatedAt:      transfer.CreatedAt,
+		FromCurrency:   transfer.FromCurrency,
+		ToAmount:       newMoney(transfer.ToAmount, transfer.ToCurrency, decimal),
+		ToCurrency:     transfer.ToCurrency,
+		ExchangeRate:   transfer.ExchangeRate,
+		RateUpdatedAt:  transfer.RateUpdatedAt,
+		ReversedAmount: newMoney(transfer.ReversedAmount, transfer.ToCurrency, decimal),
+		ReversalStatus: reversalStatusNone,
+	}
+
+	if transfer.ReversalOf.Valid {
+		rsp.ReversalOf = &transfer.ReversalOf.Int64
+	}
+
+	switch {
+	case transfer.ReversedAmount > 0 && transfer.ReversedAmount >= transfer.ToAmount:
+		rsp.ReversalStatus = reversalStatusReversed
+	case transfer.ReversedAmount > 0:
+		rsp.ReversalStatus = reversalStatusPartial
+	}
+
+	return rsp
+}
+
+type transferEntryResponse struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	Amount     money     `json:"amount"`
+	TransferID *int64    `json:"transfer_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newTransferEntryResponse(entry db.Entry, currency string, decimal bool) transferEntryResponse {
+	rsp := transferEntryResponse{
+		ID:        entry.ID,
+		AccountID: entry.AccountID,
+		Amount:    newMoney(entry.Amount, currency, decimal),
+		CreatedAt: entry.CreatedAt,
+	}
+
+	if entry.TransferID.Valid {
+		rsp.TransferID = &entry.TransferID.Int64
+	}
+
+	return rsp
+}
+
+type transferTxResponse struct {
+	Transfer    transferResponse      `json:"transfer"`
+	FromAccount accountResponse       `json:"from_account"`
+	ToAccount   accountResponse       `json:"to_account"`
+	FromEntry   transferEntryResponse `json:"from_entry"`
+	ToEntry     transferEntryResponse `json:"to_entry"`
+}
+
+func newTransferTxResponse(result db.TransferTxResult, decimal bool) transferTxResponse {
+	return transferTxResponse{
+		Transfer:    newTransferResponse(result.Transfer, decimal),
+		FromAccount: newAccountResponse(result.FromAccount, decimal),
+		ToAccount:   newAccountResponse(result.ToAccount, decimal),
+		FromEntry:   newTransferEntryResponse(result.FromEntry, result.Transfer.FromCurrency, decimal),
+		ToEntry:     newTransferEntryResponse(result.ToEntry, result.Transfer.ToCurrency, decimal),
+	}
+}
+
 type transferRequest struct {
 	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
@@ -31,13 +139,14 @@ func (s *Server) createTransfer(ctx *gin.Context) {
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
+	if !canAccessAccount(authPayload, fromAccount, permTransferFrom) {
 		err := errors.New("from account doesn't belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
-	if _, valid := s.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+	toAccount, valid := s.loadAccount(ctx, req.ToAccountID)
+	if !valid {
 		return
 	}
 
@@ -47,24 +156,113 @@ func (s *Server) createTransfer(ctx *gin.Context) {
 		Amount:        req.Amount,
 	}
 
+	if toAccount.Currency != fromAccount.Currency {
+		if valid := s.convertTransfer(ctx, &arg, fromAccount, toAccount); !valid {
+			return
+		}
+	}
+
+	if key := ctx.GetHeader(idempotencyKeyHeader); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			err := fmt.Errorf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		hash, err := requestHash(req)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		arg.Idempotency = &db.IdempotencyParams{
+			Username:    authPayload.Username,
+			Key:         key,
+			RequestHash: hash,
+		}
+	}
+
 	result, err := s.store.TransferTx(ctx, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrCashAccountNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) || errors.Is(err, db.ErrSystemAccount) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
+	if result.Replayed {
+		ctx.Header(idempotentReplayedHeader, "true")
+	}
+
+	ctx.JSON(http.StatusOK, newTransferTxResponse(result, decimalAmounts(ctx)))
 }
 
-func (s *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
-	account, err := s.store.GetAccount(ctx, accountID)
+// convertTransfer fills in the credited leg of a cross-currency transfer,
+// writing the error response when the amount cannot be converted.
+func (s *Server) convertTransfer(ctx *gin.Context, arg *db.TransferTxParams, from db.Account, to db.Account) bool {
+	rate, err := s.rates.Rate(ctx, from.Currency, to.Currency)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return account, false
+		if errors.Is(err, exchange.ErrRateNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return false
 		}
-
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	fromCurrency, fromKnown := utils.Currencies.Get(from.Currency)
+	toCurrency, toKnown := utils.Currencies.Get(to.Currency)
+	if !fromKnown || !toKnown {
+		err := fmt.Errorf("cannot convert %s to %s", from.Currency, to.Currency)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return false
+	}
+
+	arg.ToAmount, err = rate.ForMinorUnits(fromCurrency.Exponent, toCurrency.Exponent).Convert(arg.Amount)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+
+	if arg.ToAmount <= 0 {
+		err := fmt.Errorf("amount is too small to convert to %s", to.Currency)
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return false
+	}
+
+	arg.ExchangeRate = rate.String()
+	arg.RateUpdatedAt = rate.UpdatedAt
+	return true
+}
+
+// requestHash fingerprints a bound request so that a reused idempotency key
+// can be told apart from a genuine retry.
+func requestHash(req interface{}) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	account, valid := s.loadAccount(ctx, accountID)
+	if !valid {
 		return account, false
 	}
 
@@ -76,3 +274,303 @@ func (s *Server) validAccount(ctx *gin.Context, accountID int64, currency string
 
 	return account, true
 }
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := s.store.GetTransfer(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := s.store.GetAccount(ctx, accountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if canAccessAccount(authPayload, account, permReadAccount) {
+			ctx.JSON(http.StatusOK, newTransferResponse(transfer, decimalAmounts(ctx)))
+			return
+		}
+	}
+
+	err = errors.New("transfer doesn't belong to the authenticated user")
+	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+}
+
+type reverseTransferRequest struct {
+	// Amount is refunded in the currency the recipient was credited in.
+	// Leave it out to reverse whatever is left of the transfer.
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+	// Force reverses the transfer even when the recipient cannot cover it
+	// or either account is frozen. Only admins may force a reversal.
+	Force bool `json:"force"`
+}
+
+type reverseTransferResponse struct {
+	Reversal transferTxResponse `json:"reversal"`
+	Original transferResponse   `json:"original"`
+}
+
+func (s *Server) reverseTransfer(ctx *gin.Context) {
+	var uri getTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := s.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if req.Force && !roleAllows(authPayload, permForceReversal) {
+		err := fmt.Errorf("role %q is not allowed to %s", authPayload.Role, permForceReversal)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	// The money comes back out of the recipient's account, so it is theirs
+	// to refund.
+	recipient, valid := s.loadAccount(ctx, transfer.ToAccountID)
+	if !valid {
+		return
+	}
+
+	if !canAccessAccount(authPayload, recipient, permRefundTransfer) {
+		err := errors.New("transfer wasn't received by the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	result, err := s.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+		Force:      req.Force,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrTransferAlreadyReversed) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrTransferIsReversal) ||
+			errors.Is(err, db.ErrReversalExceedsTransfer) ||
+			errors.Is(err, db.ErrReversalTooSmall) ||
+			errors.Is(err, db.ErrInsufficientFunds) ||
+			errors.Is(err, db.ErrCashAccountNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	decimal := decimalAmounts(ctx)
+	ctx.JSON(http.StatusOK, reverseTransferResponse{
+		Reversal: newTransferTxResponse(result.Reversal, decimal),
+		Original: newTransferResponse(result.Original, decimal),
+	})
+}
+
+type listTransfersQuery struct {
+	Direction      string    `form:"direction" binding:"omitempty,oneof=in out"`
+	CounterpartyID int64     `form:"counterparty_id" binding:"omitempty,min=1"`
+	MinAmount      *int64    `form:"min_amount" binding:"omitempty,min=0"`
+	MaxAmount      *int64    `form:"max_amount" binding:"omitempty,min=0"`
+	From           time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To             time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor         string    `form:"cursor"`
+	PageSize       int32     `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// transferFilter is a listTransfersQuery converted to query arguments.
+type transferFilter struct {
+	Direction       sql.NullString
+	CounterpartyID  sql.NullInt64
+	MinAmount       sql.NullInt64
+	MaxAmount       sql.NullInt64
+	FromTime        sql.NullTime
+	ToTime          sql.NullTime
+	CursorCreatedAt sql.NullTime
+	CursorID        sql.NullInt64
+}
+
+func (req listTransfersQuery) filter() (transferFilter, error) {
+	var filter transferFilter
+
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return filter, errors.New("min_amount must not exceed max_amount")
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return filter, errors.New("from must be before to")
+	}
+
+	filter.Direction = sql.NullString{String: req.Direction, Valid: req.Direction != ""}
+	filter.CounterpartyID = sql.NullInt64{Int64: req.CounterpartyID, Valid: req.CounterpartyID != 0}
+	filter.FromTime = sql.NullTime{Time: req.From, Valid: !req.From.IsZero()}
+	filter.ToTime = sql.NullTime{Time: req.To, Valid: !req.To.IsZero()}
+
+	if req.MinAmount != nil {
+		filter.MinAmount = sql.NullInt64{Int64: *req.MinAmount, Valid: true}
+	}
+
+	if req.MaxAmount != nil {
+		filter.MaxAmount = sql.NullInt64{Int64: *req.MaxAmount, Valid: true}
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeKeysetCursor(req.Cursor)
+		if err != nil {
+			return filter, err
+		}
+
+		filter.CursorCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+		filter.CursorID = sql.NullInt64{Int64: cursor.ID, Valid: true}
+	}
+
+	return filter, nil
+}
+
+type listTransfersResponse struct {
+	Transfers  []transferResponse `json:"transfers"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+func newListTransfersResponse(transfers []db.Transfer, pageSize int32, decimal bool) listTransfersResponse {
+	rsp := listTransfersResponse{
+		Transfers: make([]transferResponse, len(transfers)),
+	}
+	for i, transfer := range transfers {
+		rsp.Transfers[i] = newTransferResponse(transfer, decimal)
+	}
+
+	if len(transfers) == int(pageSize) {
+		last := transfers[len(transfers)-1]
+		rsp.NextCursor = keysetCursor{CreatedAt: last.CreatedAt, ID: last.ID}.encode()
+	}
+
+	return rsp
+}
+
+func (s *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	filter, err := req.filter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ListUserTransfersParams{
+		Owner:           authPayload.Username,
+		Direction:       filter.Direction,
+		CounterpartyID:  filter.CounterpartyID,
+		MinAmount:       filter.MinAmount,
+		MaxAmount:       filter.MaxAmount,
+		FromTime:        filter.FromTime,
+		ToTime:          filter.ToTime,
+		CursorCreatedAt: filter.CursorCreatedAt,
+		CursorID:        filter.CursorID,
+		PageSize:        req.PageSize,
+	}
+
+	transfers, err := s.store.ListUserTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newListTransfersResponse(transfers, req.PageSize, decimalAmounts(ctx)))
+}
+
+type listAccountTransfersURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) listAccountTransfers(ctx *gin.Context) {
+	var uri listAccountTransfersURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listTransfersQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	filter, err := req.filter()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := s.authorizedAccount(ctx, uri.AccountID, permReadAccount); !valid {
+		return
+	}
+
+	arg := db.ListTransfersParams{
+		AccountID:       uri.AccountID,
+		Direction:       filter.Direction,
+		CounterpartyID:  filter.CounterpartyID,
+		MinAmount:       filter.MinAmount,
+		MaxAmount:       filter.MaxAmount,
+		FromTime:        filter.FromTime,
+		ToTime:          filter.ToTime,
+		CursorCreatedAt: filter.CursorCreatedAt,
+		CursorID:        filter.CursorID,
+		PageSize:        req.PageSize,
+	}
+
+	transfers, err := s.store.ListTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newListTransfersResponse(transfers, req.PageSize, decimalAmounts(ctx)))
+}
@@ -2,12 +2,18 @@ package api
 
 import (
 	"database/sql"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/token"
 	"github.com/wenealves10/gobank/utils"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -23,6 +29,8 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -32,6 +40,8 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -55,14 +65,24 @@ func (s *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.CreateUserParams{
-		Username:       req.Username,
-		FullName:       req.FullName,
-		Email:          req.Email,
-		HashedPassword: hashedPassword,
+	code, err := utils.NewSecretCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       req.Username,
+			FullName:       req.FullName,
+			Email:          req.Email,
+			HashedPassword: hashedPassword,
+		},
+		VerifyCodeHash:  utils.HashSecretCode(code),
+		VerifyExpiresAt: time.Now().Add(verifyEmailDuration),
 	}
 
-	user, err := s.store.CreateUser(ctx, arg)
+	result, err := s.store.CreateUserTx(ctx, arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -75,18 +95,48 @@ func (s *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	rsp := newUserResponse(user)
+	// The code is only sent once the user is committed, so that a slow or
+	// failing mail server neither holds the transaction open nor fails the
+	// sign up. An undelivered code can be resent after logging in.
+	if err := s.mailer.Send(ctx, verifyEmailMessage(result.User, code)); err != nil {
+		ctx.Error(err)
+	}
+
+	rsp := newUserResponse(result.User)
 	ctx.JSON(http.StatusOK, rsp)
 }
 
+var (
+	errInvalidCredentials   = errors.New("invalid credentials")
+	errTooManyLoginAttempts = errors.New("too many login attempts, try again later")
+)
+
+var (
+	unknownUserPasswordOnce sync.Once
+	unknownUserPassword     string
+)
+
+// unknownUserPasswordHash returns a bcrypt hash that logins of unknown
+// usernames are checked against, so that they take as long as the others.
+func unknownUserPasswordHash() string {
+	unknownUserPasswordOnce.Do(func() {
+		unknownUserPassword, _ = utils.HashPassword(utils.RandomString(16))
+	})
+	return unknownUserPassword
+}
+
 type loginUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"`
 	Password string `json:"password" binding:"required,min=6"`
 }
 
 type loginUserResponse struct {
-	AccessToken string       `json:"access_token"`
-	User        userResponse `json:"user"`
+	SessionID             uuid.UUID    `json:"session_id"`
+	AccessToken           string       `json:"access_token"`
+	AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+	RefreshToken          string       `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+	User                  userResponse `json:"user"`
 }
 
 func (s *Server) loginUser(ctx *gin.Context) {
@@ -96,32 +146,326 @@ func (s *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if !s.allowLoginAttempt(ctx, req.Username) {
+		return
+	}
+
 	user, err := s.store.GetUser(ctx, req.Username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	found := err == nil
+
+	// Unknown usernames and wrong passwords get the same answer, after the
+	// same bcrypt work, so that logins do not reveal who has an account.
+	hashedPassword := user.HashedPassword
+	if !found {
+		hashedPassword = unknownUserPasswordHash()
+	}
+
+	if err := utils.CheckPassword(req.Password, hashedPassword); err != nil || !found {
+		if err := s.recordLoginAttempt(ctx, req.Username, loginOutcomeInvalidCredentials); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+		return
+	}
+
+	totpSecret, err := s.store.GetTOTPSecret(ctx, user.Username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err == nil && totpSecret.ConfirmedAt.Valid {
+		s.startMFAChallenge(ctx, user)
+		return
+	}
+
+	if err := s.recordLoginAttempt(ctx, user.Username, loginOutcomeSuccess); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	s.startSession(ctx, user)
+}
+
+// startSession issues the access and refresh tokens of a user who has
+// successfully logged in.
+func (s *Server) startSession(ctx *gin.Context, user db.User) {
+	// The refresh token identifies the session, which access tokens name.
+	refreshToken, refreshPayload, err := s.tokenCreator.CreateToken(user.Username, user.Role, s.config.RefreshTokenDuration, token.Claims{
+		Type: token.TypeRefresh,
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	accessToken, accessPayload, err := s.tokenCreator.CreateToken(user.Username, user.Role, s.config.AccessTokenDuration, token.Claims{
+		SessionID: refreshPayload.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := loginUserResponse{
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		User:                  newUserResponse(user),
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// allowLoginAttempt checks the login throttle for username and the client
+// IP, writing the error response when the attempt is refused.
+func (s *Server) allowLoginAttempt(ctx *gin.Context, username string) bool {
+	retryAfter, err := s.logins.retryAfter(ctx, s.store, username, ctx.ClientIP(), time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if retryAfter <= 0 {
+		return true
+	}
+
+	if err := s.recordLoginAttempt(ctx, username, loginOutcomeThrottled); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	ctx.JSON(http.StatusTooManyRequests, errorResponse(errTooManyLoginAttempts))
+	return false
+}
+
+// recordLoginAttempt keeps an audit record of a login attempt, which also
+// feeds the login throttle.
+func (s *Server) recordLoginAttempt(ctx *gin.Context, username string, outcome string) error {
+	_, err := s.store.CreateLoginAttempt(ctx, db.CreateLoginAttemptParams{
+		Username:  username,
+		ClientIp:  ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		Outcome:   outcome,
+	})
+	return err
+}
+
+type logoutUserRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (s *Server) logoutUser(ctx *gin.Context) {
+	var req logoutUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	refreshPayload, err := verifyRefreshToken(s.tokenCreator, req.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if refreshPayload.Username != authPayload.Username {
+		err := errors.New("refresh token doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	err = s.store.LogoutTx(ctx, db.LogoutTxParams{
+		Username:             authPayload.Username,
+		AccessTokenID:        authPayload.ID,
+		AccessTokenExpiresAt: authPayload.ExpiredAt,
+		SessionID:            refreshPayload.ID,
+		SessionExpiresAt:     refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	s.revocations.revoke(authPayload)
+	s.revocations.revoke(refreshPayload)
+	ctx.Status(http.StatusNoContent)
+}
+
+// logoutAllDevices ends every session of the authenticated user and revokes
+// all of their tokens and API keys.
+func (s *Server) logoutAllDevices(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	revokedAt := time.Now()
+
+	err := s.store.LogoutTx(ctx, db.LogoutTxParams{
+		Username:             authPayload.Username,
+		AccessTokenID:        authPayload.ID,
+		AccessTokenExpiresAt: authPayload.ExpiredAt,
+		AllDevices:           true,
+		RevokedAt:            revokedAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	s.revocations.revoke(authPayload)
+	s.revocations.revokeUser(authPayload.Username, revokedAt)
+	ctx.Status(http.StatusNoContent)
+}
+
+func (s *Server) getCurrentUser(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := s.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type updateUserRequest struct {
+	FullName *string `json:"full_name" binding:"omitempty,min=1"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+}
+
+func (s *Server) updateCurrentUser(ctx *gin.Context) {
+	var req updateUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.UpdateUserParams{Username: authPayload.Username}
+
+	if req.FullName != nil {
+		arg.FullName = sql.NullString{String: *req.FullName, Valid: true}
+	}
+
+	if req.Email != nil {
+		arg.Email = sql.NullString{String: *req.Email, Valid: true}
+	}
+
+	user, err := s.store.UpdateUser(ctx, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	err = utils.CheckPassword(req.Password, user.HashedPassword)
+	// A new address has to be verified again. The change is kept even when
+	// the code cannot be sent, since it can be resent later.
+	if req.Email != nil && !user.IsEmailVerified {
+		if err := s.sendVerifyEmail(ctx, user); err != nil {
+			ctx.Error(err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// changePassword replaces the password of the authenticated user. Every
+// token issued before the change, including the one used to make it, is
+// rejected afterwards, so the user has to log in again. Their API keys are
+// revoked as well and have to be created again.
+func (s *Server) changePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := s.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = utils.CheckPassword(req.CurrentPassword, user.HashedPassword)
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
-	accessToken, err := s.tokenCreator.CreateToken(user.Username, s.config.AccessTokenDuration)
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
+		if bcrypt.ErrPasswordTooLong == err {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	rsp := loginUserResponse{
-		AccessToken: accessToken,
-		User:        newUserResponse(user),
+	changedAt := time.Now()
+	user, err = s.store.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: hashedPassword,
+		ChangedAt:      changedAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
 
-	ctx.JSON(http.StatusOK, rsp)
+	s.revocations.revokeUser(user.Username, changedAt)
+	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
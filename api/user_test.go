@@ -2,20 +2,26 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	"github.com/wenealves10/gobank/db/mocks"
 	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/mail"
+	"github.com/wenealves10/gobank/token"
 	"github.com/wenealves10/gobank/utils"
 	"go.uber.org/mock/gomock"
 )
@@ -26,11 +32,16 @@ type epCreateUserParamsMatcher struct {
 }
 
 func (e epCreateUserParamsMatcher) Matches(x interface{}) bool {
-	arg, ok := x.(db.CreateUserParams)
+	txArg, ok := x.(db.CreateUserTxParams)
 	if !ok {
 		return false
 	}
 
+	if txArg.VerifyCodeHash == "" {
+		return false
+	}
+
+	arg := txArg.CreateUserParams
 	err := utils.CheckPassword(e.password, arg.HashedPassword)
 	if err != nil {
 		return false
@@ -74,9 +85,9 @@ func TestCreateUser(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateUser(gomock.Any(), EpCreateUserParams(arg, password)).
+					CreateUserTx(gomock.Any(), EpCreateUserParams(arg, password)).
 					Times(1).
-					Return(user, nil)
+					Return(db.CreateUserTxResult{User: user}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -93,9 +104,9 @@ func TestCreateUser(t *testing.T) {
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.User{}, sql.ErrConnDone)
+					Return(db.CreateUserTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -111,9 +122,9 @@ func TestCreateUser(t *testing.T) {
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.User{}, &pq.Error{Code: "23505"})
+					Return(db.CreateUserTxResult{}, &pq.Error{Code: "23505"})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusForbidden, recorder.Code)
@@ -129,7 +140,7 @@ func TestCreateUser(t *testing.T) {
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -146,7 +157,7 @@ func TestCreateUser(t *testing.T) {
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -163,7 +174,7 @@ func TestCreateUser(t *testing.T) {
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -180,7 +191,7 @@ func TestCreateUser(t *testing.T) {
 			},
 			buildStubs: func(store *mocks.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -215,6 +226,240 @@ func TestCreateUser(t *testing.T) {
 
 }
 
+func TestCreateUserSendsVerifyEmail(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var codeHash string
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateUserTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+			require.WithinDuration(t, time.Now().Add(verifyEmailDuration), arg.VerifyExpiresAt, time.Second)
+			codeHash = arg.VerifyCodeHash
+
+			return db.CreateUserTxResult{User: user}, nil
+		})
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{
+		"username":  user.Username,
+		"full_name": user.FullName,
+		"email":     user.Email,
+		"password":  password,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	messages := server.mailer.(*mail.MemoryMailer).Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, []string{user.Email}, messages[0].To)
+	require.Equal(t, codeHash, utils.HashSecretCode(emailedCode(t, messages[0])))
+}
+
+func TestLoginAfterChangePassword(t *testing.T) {
+	testCases := []struct {
+		name    string
+		backend string
+	}{
+		{
+			name:    "Paseto",
+			backend: "paseto-local",
+		},
+		{
+			name:    "JWT",
+			backend: "jwt-hs256",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			user, password := randomUser(t)
+			newPassword := utils.RandomString(8)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var changedAt time.Time
+			current := user
+
+			store := mocks.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				AnyTimes().
+				DoAndReturn(func(context.Context, string) (db.User, error) {
+					return current, nil
+				})
+			// Tokens are revoked as IsTokenRevoked revokes them: when they
+			// were issued before the password was changed.
+			store.EXPECT().
+				IsTokenRevoked(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				DoAndReturn(func(_ context.Context, arg db.IsTokenRevokedParams) (bool, error) {
+					return current.PasswordChangedAt.After(arg.IssuedAt), nil
+				})
+			store.EXPECT().
+				ChangePasswordTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams) (db.User, error) {
+					changedAt = arg.ChangedAt
+					current.HashedPassword = arg.HashedPassword
+					current.PasswordChangedAt = arg.ChangedAt
+					return current, nil
+				})
+			allowLogin(store)
+			expectLoginAttempt(store, user.Username, loginOutcomeSuccess)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+					return db.Session{ID: arg.ID, Username: arg.Username}, nil
+				})
+
+			server := NewTestServer(t, store)
+			server.config.TokenBackend = tc.backend
+			server.config.TokenJWTSecret = utils.RandomString(32)
+			server, err := NewServer(server.config, store)
+			require.NoError(t, err)
+
+			// Start at the beginning of a second, so that the password is
+			// changed and the user logs in again within it.
+			time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+			oldToken, _, err := server.tokenCreator.CreateToken(user.Username, utils.DepositorRole, time.Minute, token.Claims{})
+			require.NoError(t, err)
+
+			data, err := json.Marshal(gin.H{
+				"current_password": password,
+				"new_password":     newPassword,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/users/me/password", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, oldToken))
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			data, err = json.Marshal(gin.H{
+				"username": user.Username,
+				"password": newPassword,
+			})
+			require.NoError(t, err)
+
+			request, err = http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			recorder = httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var rsp loginUserResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+
+			accessPayload, err := server.tokenCreator.VerifyToken(rsp.AccessToken)
+			require.NoError(t, err)
+			require.Equal(t, changedAt.Unix(), accessPayload.IssuedAt.Unix())
+
+			// The token of the new login is accepted, and the one issued
+			// before the change, in the same second, is not.
+			for token, code := range map[string]int{
+				rsp.AccessToken: http.StatusOK,
+				oldToken:        http.StatusUnauthorized,
+			} {
+				request, err = http.NewRequest(http.MethodGet, "/users/me", nil)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, token))
+
+				recorder = httptest.NewRecorder()
+				server.router.ServeHTTP(recorder, request)
+				require.Equal(t, code, recorder.Code)
+			}
+		})
+	}
+}
+
+// failingMailer fails to deliver every message.
+type failingMailer struct{}
+
+func (failingMailer) Send(context.Context, mail.Message) error {
+	return errors.New("mail server unreachable")
+}
+
+func TestCreateUserMailFails(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateUserTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.CreateUserTxResult{User: user}, nil)
+
+	server := NewTestServer(t, store)
+	server.mailer = failingMailer{}
+
+	data, err := json.Marshal(gin.H{
+		"username":  user.Username,
+		"full_name": user.FullName,
+		"email":     user.Email,
+		"password":  password,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	// The user is kept even though the code could not be sent.
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	requireBodyMatchUser(t, recorder.Body, user)
+
+	// Once mail is delivered again, the user asks for another code.
+	mailer := mail.NewMemoryMailer()
+	server.mailer = mailer
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		CreateVerifyEmail(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateVerifyEmailParams) (db.VerifyEmail, error) {
+			return db.VerifyEmail{Username: arg.Username, Email: arg.Email, CodeHash: arg.CodeHash}, nil
+		})
+
+	request, err = http.NewRequest(http.MethodPost, "/users/verify_email/resend", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	messages := mailer.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, []string{user.Email}, messages[0].To)
+}
+
 func TestLoginUser(t *testing.T) {
 	user, password := randomUser(t)
 
@@ -231,15 +476,48 @@ func TestLoginUser(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mocks.MockStore) {
+				allowLogin(store)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				expectLoginAttempt(store, user.Username, loginOutcomeSuccess)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NotEmpty(t, arg.RefreshToken)
+						require.False(t, arg.IsBlocked)
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "CreateSessionError",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				allowLogin(store)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				expectLoginAttempt(store, user.Username, loginOutcomeSuccess)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 		{
 			name: "UserNotFound",
 			body: gin.H{
@@ -247,13 +525,18 @@ func TestLoginUser(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mocks.MockStore) {
+				allowLogin(store)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrNoRows)
+				expectLoginAttempt(store, "unknownuser", loginOutcomeInvalidCredentials)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireInvalidCredentials(t, recorder)
 			},
 		},
 		{
@@ -263,13 +546,18 @@ func TestLoginUser(t *testing.T) {
 				"password": "invalid-password",
 			},
 			buildStubs: func(store *mocks.MockStore) {
+				allowLogin(store)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				expectLoginAttempt(store, user.Username, loginOutcomeInvalidCredentials)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireInvalidCredentials(t, recorder)
 			},
 		},
 		{
@@ -279,6 +567,9 @@ func TestLoginUser(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetUsernameLoginFailures(gomock.Any(), gomock.Any()).
+					Times(0)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(0)
@@ -294,15 +585,163 @@ func TestLoginUser(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mocks.MockStore) {
+				allowLogin(store)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrConnDone)
+				store.EXPECT().
+					CreateLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "RecordAttemptError",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				allowLogin(store)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginAttempt{}, sql.ErrConnDone)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "UsernameLockedOut",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetClientIPLoginFailures(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetClientIPLoginFailuresRow{}, nil)
+				store.EXPECT().
+					GetUsernameLoginFailures(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.GetUsernameLoginFailuresParams) (db.GetUsernameLoginFailuresRow, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.WithinDuration(t, time.Now().Add(-defaultLoginLockoutDuration), arg.Since, time.Second)
+						return db.GetUsernameLoginFailuresRow{
+							Failures:      defaultLoginMaxFailures,
+							FirstFailedAt: time.Now().Add(-time.Minute),
+							LastFailedAt:  time.Now().Add(-time.Minute),
+						}, nil
+					})
+				expectLoginAttempt(store, user.Username, loginOutcomeThrottled)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireRetryAfter(t, recorder, defaultLoginLockoutDuration-time.Minute)
+			},
+		},
+		{
+			name: "ClientIPLockedOut",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetClientIPLoginFailures(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetClientIPLoginFailuresRow{
+						Failures:      defaultLoginMaxFailuresPerIP,
+						FirstFailedAt: time.Now().Add(-10 * time.Minute),
+						LastFailedAt:  time.Now(),
+					}, nil)
+				store.EXPECT().
+					GetUsernameLoginFailures(gomock.Any(), gomock.Any()).
+					Times(0)
+				expectLoginAttempt(store, user.Username, loginOutcomeThrottled)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireRetryAfter(t, recorder, defaultLoginLockoutDuration-10*time.Minute)
+			},
+		},
+		{
+			name: "Backoff",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetClientIPLoginFailures(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetClientIPLoginFailuresRow{}, nil)
+				store.EXPECT().
+					GetUsernameLoginFailures(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetUsernameLoginFailuresRow{
+						Failures:      3,
+						FirstFailedAt: time.Now().Add(-time.Minute),
+						LastFailedAt:  time.Now(),
+					}, nil)
+				expectLoginAttempt(store, user.Username, loginOutcomeThrottled)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireRetryAfter(t, recorder, 4*defaultLoginBackoff)
+			},
+		},
+		{
+			name: "BackoffElapsed",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetClientIPLoginFailures(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetClientIPLoginFailuresRow{}, nil)
+				store.EXPECT().
+					GetUsernameLoginFailures(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetUsernameLoginFailuresRow{
+						Failures:      3,
+						FirstFailedAt: time.Now().Add(-time.Minute),
+						LastFailedAt:  time.Now().Add(-time.Minute),
+					}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				expectLoginAttempt(store, user.Username, loginOutcomeSuccess)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{Username: user.Username}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -331,6 +770,690 @@ func TestLoginUser(t *testing.T) {
 	}
 }
 
+// allowLogin stubs the login throttle with no failed attempts on record.
+func allowLogin(store *mocks.MockStore) {
+	store.EXPECT().
+		GetClientIPLoginFailures(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.GetClientIPLoginFailuresRow{}, nil)
+	store.EXPECT().
+		GetUsernameLoginFailures(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.GetUsernameLoginFailuresRow{}, nil)
+}
+
+func expectLoginAttempt(store *mocks.MockStore, username string, outcome string) {
+	store.EXPECT().
+		CreateLoginAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateLoginAttemptParams) (db.LoginAttempt, error) {
+			if arg.Username != username || arg.Outcome != outcome {
+				return db.LoginAttempt{}, fmt.Errorf("unexpected login attempt %+v", arg)
+			}
+			return db.LoginAttempt{Username: arg.Username, ClientIp: arg.ClientIp, Outcome: arg.Outcome}, nil
+		})
+}
+
+func requireInvalidCredentials(t *testing.T, recorder *httptest.ResponseRecorder) {
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.JSONEq(t, `{"error": "invalid credentials"}`, recorder.Body.String())
+}
+
+func requireRetryAfter(t *testing.T, recorder *httptest.ResponseRecorder, wait time.Duration) {
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	require.InDelta(t, wait.Seconds(), retryAfter, 1)
+}
+
+func TestLogoutUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildBody     func(refreshToken string) gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator)
+		buildStubs    func(store *mocks.MockStore, refreshPayload *token.Payload)
+		checkResponse func(t *testing.T, server *Server, request *http.Request, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore, refreshPayload *token.Payload) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.LogoutTxParams) error {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, refreshPayload.ID, arg.SessionID)
+						require.WithinDuration(t, refreshPayload.ExpiredAt, arg.SessionExpiresAt, time.Second)
+						require.NotEqual(t, refreshPayload.ID, arg.AccessTokenID)
+						require.False(t, arg.AllDevices)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, server *Server, request *http.Request, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+
+				// The access token is now rejected without asking the store.
+				recorder = httptest.NewRecorder()
+				server.router.ServeHTTP(recorder, request)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+			},
+			buildStubs: func(store *mocks.MockStore, refreshPayload *token.Payload) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, request *http.Request, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MissingRefreshToken",
+			buildBody: func(refreshToken string) gin.H {
+				return gin.H{}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore, refreshPayload *token.Payload) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, request *http.Request, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRefreshToken",
+			buildBody: func(refreshToken string) gin.H {
+				return gin.H{"refresh_token": "invalid-token"}
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore, refreshPayload *token.Payload) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, request *http.Request, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RefreshTokenOfAnotherUser",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, "unauthorized_user", utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore, refreshPayload *token.Payload) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, request *http.Request, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore, refreshPayload *token.Payload) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ErrSessionNotFound)
+			},
+			checkResponse: func(t *testing.T, server *Server, request *http.Request, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenCreator token.TokenCreator) {
+				addAuthorization(t, request, tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mocks.MockStore, refreshPayload *token.Payload) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, request *http.Request, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			server := NewTestServer(t, store)
+
+			refreshToken, refreshPayload, err := server.tokenCreator.CreateToken(user.Username, utils.DepositorRole, time.Hour, token.Claims{Type: token.TypeRefresh})
+			require.NoError(t, err)
+			tc.buildStubs(store, refreshPayload)
+
+			body := gin.H{"refresh_token": refreshToken}
+			if tc.buildBody != nil {
+				body = tc.buildBody(refreshToken)
+			}
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			url := "/users/logout"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenCreator)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, request, recorder)
+		})
+	}
+}
+
+func TestLogoutAllDevicesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.LogoutTxParams) error {
+						require.Equal(t, user.Username, arg.Username)
+						require.True(t, arg.AllDevices)
+						require.WithinDuration(t, time.Now(), arg.RevokedAt, time.Second)
+						return nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					LogoutTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/users/logout_all"
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetCurrentUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/me", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateCurrentUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	newEmail := utils.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "UpdateEmail",
+			body: gin.H{
+				"email": newEmail,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.UpdateUserParams{
+					Email:    sql.NullString{String: newEmail, Valid: true},
+					Username: user.Username,
+				}
+
+				updated := user
+				updated.Email = newEmail
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(updated, nil)
+
+				// The new address is sent a verification code.
+				store.EXPECT().
+					CreateVerifyEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateVerifyEmailParams) (db.VerifyEmail, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, newEmail, arg.Email)
+						return db.VerifyEmail{Username: arg.Username, Email: arg.Email}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				updated := user
+				updated.Email = newEmail
+				requireBodyMatchUser(t, recorder.Body, updated)
+			},
+		},
+		{
+			name: "UpdateFullName",
+			body: gin.H{
+				"full_name": "New Name",
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				arg := db.UpdateUserParams{
+					FullName: sql.NullString{String: "New Name", Valid: true},
+					Username: user.Username,
+				}
+
+				updated := user
+				updated.FullName = "New Name"
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DuplicateEmail",
+			body: gin.H{
+				"email": newEmail,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidEmail",
+			body: gin.H{
+				"email": "invalid-email",
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EmptyFullName",
+			body: gin.H{
+				"full_name": "",
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPatch, "/users/me", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestChangePasswordAPI(t *testing.T) {
+	user, password := randomUser(t)
+	newPassword := utils.RandomString(8)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"current_password": password,
+				"new_password":     newPassword,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ChangePasswordTxParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NoError(t, utils.CheckPassword(newPassword, arg.HashedPassword))
+						require.WithinDuration(t, time.Now(), arg.ChangedAt, time.Second)
+
+						updated := user
+						updated.HashedPassword = arg.HashedPassword
+						updated.PasswordChangedAt = arg.ChangedAt
+						return updated, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name: "WrongCurrentPassword",
+			body: gin.H{
+				"current_password": "wrong-password",
+				"new_password":     newPassword,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NewPasswordTooShort",
+			body: gin.H{
+				"current_password": password,
+				"new_password":     "123",
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{
+				"current_password": password,
+				"new_password":     newPassword,
+			},
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ChangePasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/users/me/password", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenCreator, authorizationTypeBearer, user.Username, utils.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestChangePasswordRevokesToken(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		ChangePasswordTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(user, nil)
+
+	server := NewTestServer(t, store)
+	accessToken, _, err := server.tokenCreator.CreateToken(user.Username, utils.DepositorRole, time.Minute, token.Claims{})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(gin.H{
+		"current_password": password,
+		"new_password":     utils.RandomString(8),
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPut, "/users/me/password", bytes.NewReader(data))
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// The token used to change the password was issued before the change.
+	request, err = http.NewRequest(http.MethodGet, "/users/me", nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestRefreshTokenAsBearer(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		Times(0)
+	store.EXPECT().
+		LogoutTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	server := NewTestServer(t, store)
+	accessToken, _, err := server.tokenCreator.CreateToken(user.Username, utils.DepositorRole, time.Minute, token.Claims{})
+	require.NoError(t, err)
+	refreshToken, refreshPayload, err := server.tokenCreator.CreateToken(user.Username, utils.DepositorRole, time.Hour, token.Claims{Type: token.TypeRefresh})
+	require.NoError(t, err)
+
+	getCurrentUser := func() *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodGet, "/users/me", nil)
+		require.NoError(t, err)
+		request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// A refresh token does not authenticate requests while its session is
+	// live.
+	require.Equal(t, http.StatusUnauthorized, getCurrentUser().Code)
+
+	data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users/logout", bytes.NewReader(data))
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	// Nor after logging out, when the refresh token is revoked as well.
+	require.Equal(t, http.StatusUnauthorized, getCurrentUser().Code)
+
+	revoked, err := server.revocations.isRevoked(context.Background(), refreshPayload)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
 func randomUser(t *testing.T) (user db.User, password string) {
 	password = utils.RandomString(6)
 	hashedPassword, err := utils.HashPassword(password)
@@ -12,3 +12,11 @@ var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
 
 	return false
 }
+
+var validScope validator.Func = func(fl validator.FieldLevel) bool {
+	if scope, ok := fl.Field().Interface().(string); ok {
+		return isValidScope(scope)
+	}
+
+	return false
+}
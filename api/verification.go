@@ -0,0 +1,211 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/mail"
+	"github.com/wenealves10/gobank/token"
+	"github.com/wenealves10/gobank/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// verifyEmailDuration is how long an email verification code is valid.
+	verifyEmailDuration = 24 * time.Hour
+	// passwordResetDuration is how long a password reset code is valid.
+	passwordResetDuration = 30 * time.Minute
+)
+
+func verifyEmailMessage(user db.User, code string) mail.Message {
+	return mail.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nUse this code to verify your email address:\n\n%s\n\nIt expires in %.0f hours.\n",
+			user.FullName, code, verifyEmailDuration.Hours()),
+	}
+}
+
+func passwordResetMessage(user db.User, code string) mail.Message {
+	return mail.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nUse this code to choose a new password:\n\n%s\n\nIt expires in %.0f minutes. If you did not ask to reset your password, you can ignore this email.\n",
+			user.FullName, code, passwordResetDuration.Minutes()),
+	}
+}
+
+// sendVerifyEmail sends the user a new code verifying their current email.
+// Codes sent earlier stay valid until they expire.
+func (s *Server) sendVerifyEmail(ctx *gin.Context, user db.User) error {
+	code, err := utils.NewSecretCode()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		CodeHash:  utils.HashSecretCode(code),
+		ExpiresAt: time.Now().Add(verifyEmailDuration),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, verifyEmailMessage(user, code))
+}
+
+type verifyEmailRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (s *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := s.store.VerifyEmailTx(ctx, utils.HashSecretCode(req.Code))
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCode) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+func (s *Server) resendVerifyEmail(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := s.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if user.IsEmailVerified {
+		err := errors.New("email address is already verified")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	if err := s.sendVerifyEmail(ctx, user); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+type requestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// requestPasswordReset emails a password reset code to the user with the
+// given address. It answers the same whether or not such a user exists, so
+// that it cannot be used to find out who has an account. Codes are only
+// sent to verified addresses.
+func (s *Server) requestPasswordReset(ctx *gin.Context) {
+	var req requestPasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := s.store.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Status(http.StatusAccepted)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !user.IsEmailVerified {
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+
+	code, err := utils.NewSecretCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = s.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		Username:  user.Username,
+		CodeHash:  utils.HashSecretCode(code),
+		ExpiresAt: time.Now().Add(passwordResetDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := s.mailer.Send(ctx, passwordResetMessage(user, code)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+type resetPasswordRequest struct {
+	Code        string `json:"code" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// resetPassword sets a new password with a code from requestPasswordReset.
+// Like changePassword, it rejects every token issued before.
+func (s *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		if bcrypt.ErrPasswordTooLong == err {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	changedAt := time.Now()
+	user, err := s.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		CodeHash:       utils.HashSecretCode(req.Code),
+		HashedPassword: hashedPassword,
+		ChangedAt:      changedAt,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCode) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	s.revocations.revokeUser(user.Username, changedAt)
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
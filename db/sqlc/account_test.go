@@ -2,7 +2,6 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"testing"
 	"time"
 
@@ -11,12 +10,15 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountInCurrency(t, utils.RandomCurrency())
+}
 
+func createRandomAccountInCurrency(t *testing.T, currency string) Account {
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
-		Currency: utils.RandomCurrency(),
+		Currency: currency,
 		Balance:  utils.RandomMoney(),
 	}
 
@@ -70,18 +72,6 @@ func TestUpdateAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
-func TestDeleteAccount(t *testing.T) {
-	account1 := createRandomAccount(t)
-
-	err := testQueries.DeleteAccount(context.Background(), account1.ID)
-	require.NoError(t, err)
-
-	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
-	require.Error(t, err)
-	require.EqualError(t, err, sql.ErrNoRows.Error())
-	require.Empty(t, account2)
-}
-
 func TestListAccount(t *testing.T) {
 	var lastAccount Account
 	for i := 0; i < 10; i++ {
@@ -103,3 +93,77 @@ func TestListAccount(t *testing.T) {
 		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
+
+func TestUpdateAccountOverdraftLimit(t *testing.T) {
+	account1 := createRandomAccount(t)
+	require.Zero(t, account1.OverdraftLimit)
+
+	arg := UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: utils.RandomMoney(),
+	}
+
+	account2, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, account2)
+
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, account1.Balance, account2.Balance)
+	require.Equal(t, arg.OverdraftLimit, account2.OverdraftLimit)
+}
+
+func TestUpdateAccountStatus(t *testing.T) {
+	account1 := createRandomAccount(t)
+	require.Equal(t, AccountStatusActive, account1.Status)
+	require.False(t, account1.ClosedAt.Valid)
+
+	account2, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status: AccountStatusClosed,
+		ID:     account1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, AccountStatusClosed, account2.Status)
+	require.True(t, account2.ClosedAt.Valid)
+
+	account3, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status: AccountStatusActive,
+		ID:     account1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, account3.Status)
+	require.False(t, account3.ClosedAt.Valid)
+}
+
+func TestReopenFrozenAccount(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	_, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account.ID,
+		From:      AccountStatusActive,
+		Status:    AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+
+	// Reopening only applies to closed accounts, so it cannot lift a freeze.
+	_, err = store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account.ID,
+		From:      AccountStatusClosed,
+		Status:    AccountStatusActive,
+	})
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	frozen, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, frozen.Status)
+
+	// Unfreezing does.
+	unfrozen, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account.ID,
+		From:      AccountStatusFrozen,
+		Status:    AccountStatusActive,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, unfrozen.Status)
+}
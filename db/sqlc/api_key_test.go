@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func createRandomAPIKey(t *testing.T, user User) ApiKey {
+	arg := CreateAPIKeyParams{
+		Username:   user.Username,
+		Name:       utils.RandomString(8),
+		Prefix:     "gbk_" + utils.RandomString(12),
+		SecretHash: utils.HashSecretCode(utils.RandomString(32)),
+		Scopes:     []string{"accounts:read", "transfers:read"},
+		ExpiresAt:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	apiKey, err := testQueries.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, apiKey.Username)
+	require.Equal(t, arg.Name, apiKey.Name)
+	require.Equal(t, arg.Prefix, apiKey.Prefix)
+	require.Equal(t, arg.SecretHash, apiKey.SecretHash)
+	require.Equal(t, arg.Scopes, apiKey.Scopes)
+	require.WithinDuration(t, arg.ExpiresAt.Time, apiKey.ExpiresAt.Time, time.Second)
+	require.False(t, apiKey.LastUsedAt.Valid)
+	require.False(t, apiKey.RevokedAt.Valid)
+
+	return apiKey
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	createRandomAPIKey(t, createRandomUser(t))
+}
+
+func TestCreateAPIKeyWithoutScopes(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.CreateAPIKey(context.Background(), CreateAPIKeyParams{
+		Username:   user.Username,
+		Name:       utils.RandomString(8),
+		Prefix:     "gbk_" + utils.RandomString(12),
+		SecretHash: utils.HashSecretCode(utils.RandomString(32)),
+		Scopes:     []string{},
+	})
+	require.Error(t, err)
+}
+
+func TestGetAPIKeyByPrefix(t *testing.T) {
+	apiKey1 := createRandomAPIKey(t, createRandomUser(t))
+
+	apiKey2, err := testQueries.GetAPIKeyByPrefix(context.Background(), apiKey1.Prefix)
+	require.NoError(t, err)
+	require.Equal(t, apiKey1.ID, apiKey2.ID)
+	require.Equal(t, apiKey1.Scopes, apiKey2.Scopes)
+
+	_, err = testQueries.GetAPIKeyByPrefix(context.Background(), "gbk_"+utils.RandomString(12))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListAPIKeys(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomAPIKey(t, user)
+	}
+	createRandomAPIKey(t, createRandomUser(t))
+
+	apiKeys, err := testQueries.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 3)
+
+	for _, apiKey := range apiKeys {
+		require.Equal(t, user.Username, apiKey.Username)
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	apiKey := createRandomAPIKey(t, createRandomUser(t))
+
+	revoked, err := testQueries.RevokeAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	// Revoking again keeps the time the key was first revoked at.
+	again, err := testQueries.RevokeAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.Equal(t, revoked.RevokedAt.Time, again.RevokedAt.Time)
+}
+
+func TestRevokeUserAPIKeys(t *testing.T) {
+	user := createRandomUser(t)
+	apiKey1 := createRandomAPIKey(t, user)
+	apiKey2 := createRandomAPIKey(t, user)
+	other := createRandomAPIKey(t, createRandomUser(t))
+
+	revoked, err := testQueries.RevokeAPIKey(context.Background(), apiKey1.ID)
+	require.NoError(t, err)
+
+	err = testQueries.RevokeUserAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	// A key revoked before keeps its time, and the others of the user are
+	// revoked.
+	apiKey1, err = testQueries.GetAPIKey(context.Background(), apiKey1.ID)
+	require.NoError(t, err)
+	require.Equal(t, revoked.RevokedAt.Time, apiKey1.RevokedAt.Time)
+
+	apiKey2, err = testQueries.GetAPIKey(context.Background(), apiKey2.ID)
+	require.NoError(t, err)
+	require.True(t, apiKey2.RevokedAt.Valid)
+
+	other, err = testQueries.GetAPIKey(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.False(t, other.RevokedAt.Valid)
+}
+
+func TestRecordAPIKeyUsage(t *testing.T) {
+	apiKey := createRandomAPIKey(t, createRandomUser(t))
+
+	arg := RecordAPIKeyUsageParams{
+		ApiKeyID:  apiKey.ID,
+		Method:    "GET",
+		Path:      "/accounts",
+		ClientIp:  "10.0.0.1",
+		UserAgent: "batch-job/1.0",
+	}
+	err := testQueries.RecordAPIKeyUsage(context.Background(), arg)
+	require.NoError(t, err)
+
+	used, err := testQueries.GetAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.True(t, used.LastUsedAt.Valid)
+	require.WithinDuration(t, time.Now(), used.LastUsedAt.Time, time.Second)
+
+	usages, err := testQueries.ListAPIKeyUsages(context.Background(), ListAPIKeyUsagesParams{
+		ApiKeyID: apiKey.ID,
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	require.Equal(t, arg.Method, usages[0].Method)
+	require.Equal(t, arg.Path, usages[0].Path)
+	require.Equal(t, arg.ClientIp, usages[0].ClientIp)
+	require.Equal(t, arg.UserAgent, usages[0].UserAgent)
+}
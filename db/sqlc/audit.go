@@ -0,0 +1,303 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of resource an audit event can be about.
+const (
+	AuditResourceAccount           = "account"
+	AuditResourceTransfer          = "transfer"
+	AuditResourceHold              = "hold"
+	AuditResourceUser              = "user"
+	AuditResourceSession           = "session"
+	AuditResourceScheduledTransfer = "scheduled_transfer"
+	AuditResourceAPIKey            = "api_key"
+)
+
+// auditGenesisHash is the previous hash of the first event of the chain.
+const auditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// auditVerifyBatchSize is how many events VerifyAuditChain loads at a time.
+const auditVerifyBatchSize = 500
+
+// AuditContext describes who asked for the operations made with a context,
+// for the audit events the Store records for them.
+type AuditContext struct {
+	// Actor is the user the operations are made by. Operations without
+	// one are recorded as made by the user they act on, or by the system.
+	Actor     string
+	RequestID string
+	ClientIP  string
+	UserAgent string
+}
+
+type auditContextKey struct{}
+
+// WithAuditContext returns a copy of ctx that carries audit.
+func WithAuditContext(ctx context.Context, audit AuditContext) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, audit)
+}
+
+// WithAuditActor returns a copy of ctx whose audit context names actor,
+// keeping the rest of it.
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	audit := AuditContextFrom(ctx)
+	audit.Actor = actor
+	return WithAuditContext(ctx, audit)
+}
+
+// AuditContextFrom returns the audit context carried by ctx, if any.
+func AuditContextFrom(ctx context.Context) AuditContext {
+	audit, _ := ctx.Value(auditContextKey{}).(AuditContext)
+	return audit
+}
+
+// auditEvent is a state change to record. Before is nil for resources that
+// are created and After for resources that are deleted.
+type auditEvent struct {
+	// Actor is used when the context names none.
+	Actor        string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Before       interface{}
+	After        interface{}
+}
+
+// recordAudit appends an event to the audit log in the caller's transaction.
+// Events are chained in commit order: the chain stays locked until the
+// transaction ends, so this must be the last statement of it.
+func recordAudit(ctx context.Context, q *Queries, event auditEvent) error {
+	audit := AuditContextFrom(ctx)
+
+	actor := audit.Actor
+	if actor == "" {
+		actor = event.Actor
+	}
+	if actor == "" {
+		actor = SystemUsername
+	}
+
+	before, err := json.Marshal(event.Before)
+	if err != nil {
+		return err
+	}
+
+	after, err := json.Marshal(event.After)
+	if err != nil {
+		return err
+	}
+
+	err = q.LockAuditChain(ctx)
+	if err != nil {
+		return err
+	}
+
+	prevHash, err := q.GetLastAuditEventHash(ctx)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		prevHash = auditGenesisHash
+	}
+
+	arg := CreateAuditEventParams{
+		Actor:        actor,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		RequestID:    audit.RequestID,
+		ClientIp:     audit.ClientIP,
+		UserAgent:    audit.UserAgent,
+		Before:       before,
+		After:        after,
+		PrevHash:     prevHash,
+		// Postgres keeps microseconds, and the hash must match what is read back.
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	arg.Hash = auditEventHash(AuditEvent{
+		Actor:        arg.Actor,
+		Action:       arg.Action,
+		ResourceType: arg.ResourceType,
+		ResourceID:   arg.ResourceID,
+		RequestID:    arg.RequestID,
+		ClientIp:     arg.ClientIp,
+		UserAgent:    arg.UserAgent,
+		Before:       arg.Before,
+		After:        arg.After,
+		PrevHash:     arg.PrevHash,
+		CreatedAt:    arg.CreatedAt,
+	})
+
+	_, err = q.CreateAuditEvent(ctx, arg)
+	return err
+}
+
+// auditEventHash hashes an event together with the hash of the one before
+// it, so that changing, removing or reordering any event breaks the chain
+// from there on.
+func auditEventHash(event AuditEvent) string {
+	fields, _ := json.Marshal([]string{
+		event.Actor,
+		event.Action,
+		event.ResourceType,
+		event.ResourceID,
+		event.RequestID,
+		event.ClientIp,
+		event.UserAgent,
+		string(event.Before),
+		string(event.After),
+		event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(append([]byte(event.PrevHash), fields...))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditChainError is returned by VerifyAuditChain for the first event that
+// does not match the chain.
+type AuditChainError struct {
+	EventID int64
+}
+
+func (e *AuditChainError) Error() string {
+	return fmt.Sprintf("audit chain is broken at event %d", e.EventID)
+}
+
+type AuditChainResult struct {
+	// Events is the number of events verified.
+	Events int64 `json:"events"`
+	// LastHash is the hash of the last event. Events removed from the end
+	// of the log can only be noticed by comparing it with a hash kept
+	// elsewhere.
+	LastHash string `json:"last_hash"`
+}
+
+// VerifyAuditChain recomputes the hash of every audit event, in order, and
+// checks that each one follows the event before it.
+func VerifyAuditChain(ctx context.Context, q Querier) (AuditChainResult, error) {
+	result := AuditChainResult{LastHash: auditGenesisHash}
+
+	var afterID int64
+	for {
+		events, err := q.ListAuditEventsAfter(ctx, ListAuditEventsAfterParams{
+			AfterID:   afterID,
+			BatchSize: auditVerifyBatchSize,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, event := range events {
+			if event.PrevHash != result.LastHash || auditEventHash(event) != event.Hash {
+				return result, &AuditChainError{EventID: event.ID}
+			}
+
+			result.Events++
+			result.LastHash = event.Hash
+			afterID = event.ID
+		}
+
+		if len(events) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// auditUser is what the audit log keeps of a user: all but the password.
+type auditUser struct {
+	Username          string    `json:"username"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	TokensRevokedAt   time.Time `json:"tokens_revoked_at"`
+}
+
+func newAuditUser(user User) auditUser {
+	return auditUser{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+		PasswordChangedAt: user.PasswordChangedAt,
+		TokensRevokedAt:   user.TokensRevokedAt,
+	}
+}
+
+// auditSession is what the audit log keeps of a session: all but the
+// refresh token.
+type auditSession struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	UserAgent string    `json:"user_agent"`
+	ClientIP  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func newAuditSession(session Session) auditSession {
+	return auditSession{
+		ID:        session.ID,
+		Username:  session.Username,
+		UserAgent: session.UserAgent,
+		ClientIP:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
+
+// auditAPIKey is what the audit log keeps of an API key: all but the hash
+// of its secret.
+type auditAPIKey struct {
+	ID        int64        `json:"id"`
+	Username  string       `json:"username"`
+	Name      string       `json:"name"`
+	Prefix    string       `json:"prefix"`
+	Scopes    []string     `json:"scopes"`
+	ExpiresAt sql.NullTime `json:"expires_at"`
+	RevokedAt sql.NullTime `json:"revoked_at"`
+}
+
+func newAuditAPIKey(apiKey ApiKey) auditAPIKey {
+	return auditAPIKey{
+		ID:        apiKey.ID,
+		Username:  apiKey.Username,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Scopes:    apiKey.Scopes,
+		ExpiresAt: apiKey.ExpiresAt,
+		RevokedAt: apiKey.RevokedAt,
+	}
+}
+
+// auditTOTP is what the audit log keeps of a TOTP secret: whether it is
+// confirmed, never the secret itself.
+type auditTOTP struct {
+	Username    string       `json:"username"`
+	ConfirmedAt sql.NullTime `json:"confirmed_at"`
+}
+
+func newAuditTOTP(secret TotpSecret) auditTOTP {
+	return auditTOTP{
+		Username:    secret.Username,
+		ConfirmedAt: secret.ConfirmedAt,
+	}
+}
+
+func auditID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+// listResourceAuditEvents returns the audit events of a resource, most
+// recent first.
+func listResourceAuditEvents(t *testing.T, resourceType string, resourceID string) []AuditEvent {
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		ResourceType: sql.NullString{String: resourceType, Valid: true},
+		ResourceID:   sql.NullString{String: resourceID, Valid: true},
+		PageSize:     50,
+	})
+	require.NoError(t, err)
+	return events
+}
+
+func TestRecordAudit(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	audit := AuditContext{
+		Actor:     user.Username,
+		RequestID: utils.RandomString(12),
+		ClientIP:  "10.0.0.1",
+		UserAgent: "audit-test/1.0",
+	}
+	ctx := WithAuditContext(context.Background(), audit)
+
+	account, err := store.CreateAccount(ctx, CreateAccountParams{
+		Owner:    user.Username,
+		Currency: utils.USD,
+	})
+	require.NoError(t, err)
+
+	frozen, err := store.UpdateAccountStatusTx(ctx, UpdateAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+
+	events := listResourceAuditEvents(t, AuditResourceAccount, auditID(account.ID))
+	require.Len(t, events, 2)
+
+	updated, created := events[0], events[1]
+	require.Equal(t, "account.create", created.Action)
+	require.Equal(t, "account.update_status", updated.Action)
+
+	for _, event := range events {
+		require.Equal(t, audit.Actor, event.Actor)
+		require.Equal(t, audit.RequestID, event.RequestID)
+		require.Equal(t, audit.ClientIP, event.ClientIp)
+		require.Equal(t, audit.UserAgent, event.UserAgent)
+		require.Equal(t, auditEventHash(event), event.Hash)
+	}
+
+	require.JSONEq(t, "null", string(created.Before))
+
+	var before, after Account
+	require.NoError(t, json.Unmarshal(updated.Before, &before))
+	require.NoError(t, json.Unmarshal(updated.After, &after))
+	require.Equal(t, AccountStatusActive, before.Status)
+	require.Equal(t, frozen.Status, after.Status)
+}
+
+func TestRecordAuditWithoutActor(t *testing.T) {
+	store := NewStore(testDB)
+
+	// A login is made by the user logging in.
+	user := createRandomUser(t)
+	session, err := store.CreateSession(context.Background(), CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: utils.RandomString(32),
+		UserAgent:    utils.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	events := listResourceAuditEvents(t, AuditResourceSession, session.ID.String())
+	require.Len(t, events, 1)
+	require.Equal(t, "user.login", events[0].Action)
+	require.Equal(t, user.Username, events[0].Actor)
+	require.NotContains(t, string(events[0].After), session.RefreshToken)
+}
+
+func TestRecordAuditRollback(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	// Closing an account with money in it fails, and records nothing.
+	_, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusClosed,
+	})
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	events := listResourceAuditEvents(t, AuditResourceAccount, auditID(account.ID))
+	require.Empty(t, events)
+}
+
+func TestAuditEventsAppendOnly(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: utils.USD,
+	})
+	require.NoError(t, err)
+
+	for _, query := range []string{
+		"UPDATE audit_events SET actor = 'mallory'",
+		"DELETE FROM audit_events",
+		"TRUNCATE audit_events",
+	} {
+		_, err := testDB.Exec(query)
+		require.Error(t, err, query)
+	}
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	account, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: utils.USD,
+	})
+	require.NoError(t, err)
+
+	result, err := VerifyAuditChain(context.Background(), testQueries)
+	require.NoError(t, err)
+	require.NotZero(t, result.Events)
+
+	last, err := testQueries.GetLastAuditEventHash(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, last, result.LastHash)
+
+	// Changing what an event says breaks the chain at it.
+	event := listResourceAuditEvents(t, AuditResourceAccount, auditID(account.ID))[0]
+	tampered := tamperedQuerier{Querier: testQueries, eventID: event.ID}
+
+	_, err = VerifyAuditChain(context.Background(), tampered)
+
+	var chainErr *AuditChainError
+	require.True(t, errors.As(err, &chainErr))
+	require.Equal(t, event.ID, chainErr.EventID)
+}
+
+func TestAuditEventHash(t *testing.T) {
+	event := AuditEvent{
+		Actor:        utils.RandomOwner(),
+		Action:       "transfer.create",
+		ResourceType: AuditResourceTransfer,
+		ResourceID:   "1",
+		Before:       json.RawMessage(`null`),
+		After:        json.RawMessage(`{"amount":10}`),
+		PrevHash:     auditGenesisHash,
+		CreatedAt:    time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	hash := auditEventHash(event)
+	require.Len(t, hash, 64)
+
+	// The same instant in another time zone hashes the same.
+	moved := event
+	moved.CreatedAt = event.CreatedAt.In(time.FixedZone("UTC-3", -3*60*60))
+	require.Equal(t, hash, auditEventHash(moved))
+
+	for _, change := range []func(event *AuditEvent){
+		func(event *AuditEvent) { event.Actor = "mallory" },
+		func(event *AuditEvent) { event.After = json.RawMessage(`{"amount":1000}`) },
+		func(event *AuditEvent) { event.PrevHash = hash },
+		func(event *AuditEvent) { event.CreatedAt = event.CreatedAt.Add(time.Microsecond) },
+	} {
+		changed := event
+		change(&changed)
+		require.NotEqual(t, hash, auditEventHash(changed))
+	}
+}
+
+// tamperedQuerier returns the audit events of the database with the After
+// of one of them rewritten.
+type tamperedQuerier struct {
+	Querier
+	eventID int64
+}
+
+func (q tamperedQuerier) ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error) {
+	events, err := q.Querier.ListAuditEventsAfter(ctx, arg)
+	for i := range events {
+		if events[i].ID == q.eventID {
+			events[i].After = json.RawMessage(`{"balance":1000000}`)
+		}
+	}
+	return events, err
+}
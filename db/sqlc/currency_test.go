@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, currencies)
+
+	byCode := make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+	}
+
+	for _, code := range []string{utils.USD, utils.EUR, utils.CAD} {
+		currency, ok := byCode[code]
+		require.True(t, ok, code)
+		require.True(t, currency.Enabled)
+		require.Equal(t, int32(2), currency.Exponent)
+	}
+}
+
+func TestCreateAccountUnknownCurrency(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "XXX",
+	})
+	require.Error(t, err)
+}
+
+func TestUpsertCurrency(t *testing.T) {
+	// Codes starting with Z are not used by ISO 4217.
+	code := "Z" + strings.ToUpper(utils.RandomString(2))
+
+	currency, err := testQueries.UpsertCurrency(context.Background(), UpsertCurrencyParams{
+		Code:     code,
+		Exponent: 3,
+		Symbol:   code,
+		Enabled:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, code, currency.Code)
+	require.Equal(t, int32(3), currency.Exponent)
+	require.True(t, currency.Enabled)
+
+	// Accounts can be opened in it, and money booked against its cash
+	// account.
+	createRandomAccountInCurrency(t, code)
+
+	cashAccount, err := testQueries.GetCashAccount(context.Background(), code)
+	require.NoError(t, err)
+	require.Equal(t, SystemUsername, cashAccount.Owner)
+
+	// The symbol and status are updated, and the exponent is kept.
+	updated, err := testQueries.UpsertCurrency(context.Background(), UpsertCurrencyParams{
+		Code:     code,
+		Exponent: 2,
+		Symbol:   "z",
+		Enabled:  false,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(3), updated.Exponent)
+	require.Equal(t, "z", updated.Symbol)
+	require.False(t, updated.Enabled)
+	require.Equal(t, currency.CreatedAt, updated.CreatedAt)
+}
@@ -7,31 +7,72 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
-INSERT INTO entries (account_id, amount) VALUES ($1, $2) RETURNING id, account_id, amount, created_at
+INSERT INTO entries (
+    account_id,
+    amount,
+    transfer_id,
+    kind,
+    reference,
+    description,
+    ledger_transaction_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, account_id, amount, created_at, transfer_id, kind, reference, description, ledger_transaction_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID           int64         `json:"account_id"`
+	Amount              int64         `json:"amount"`
+	TransferID          sql.NullInt64 `json:"transfer_id"`
+	Kind                string        `json:"kind"`
+	Reference           string        `json:"reference"`
+	Description         string        `json:"description"`
+	LedgerTransactionID int64         `json:"ledger_transaction_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.Kind,
+		arg.Reference,
+		arg.Description,
+		arg.LedgerTransactionID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Kind,
+		&i.Reference,
+		&i.Description,
+		&i.LedgerTransactionID,
 	)
 	return i, err
 }
 
+const getAccountEntriesTotal = `-- name: GetAccountEntriesTotal :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries WHERE account_id = $1
+`
+
+func (q *Queries) GetAccountEntriesTotal(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAccountEntriesTotal, accountID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries WHERE id = $1 LIMIT 1
+SELECT id, account_id, amount, created_at, transfer_id, kind, reference, description, ledger_transaction_id FROM entries WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
@@ -42,12 +83,113 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Kind,
+		&i.Reference,
+		&i.Description,
+		&i.LedgerTransactionID,
 	)
 	return i, err
 }
 
+const listAccountStatement = `-- name: ListAccountStatement :many
+SELECT
+    s.id,
+    s.account_id,
+    s.amount,
+    s.kind,
+    s.reference,
+    s.description,
+    s.transfer_id,
+    s.counterparty_account_id,
+    s.running_balance,
+    s.created_at
+FROM (
+    SELECT
+        e.id,
+        e.account_id,
+        e.amount,
+        e.kind,
+        e.reference,
+        e.description,
+        e.transfer_id,
+        e.created_at,
+        (CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END)::bigint AS counterparty_account_id,
+        (SUM(e.amount) OVER (ORDER BY e.id ROWS UNBOUNDED PRECEDING))::bigint AS running_balance
+    FROM entries e
+    LEFT JOIN transfers t ON t.id = e.transfer_id
+    WHERE e.account_id = $1
+) s
+WHERE ($2::timestamptz IS NULL OR s.created_at >= $2)
+  AND ($3::timestamptz IS NULL OR s.created_at < $3)
+  AND s.id > $4
+ORDER BY s.id
+LIMIT $5
+`
+
+type ListAccountStatementParams struct {
+	AccountID int64        `json:"account_id"`
+	FromTime  sql.NullTime `json:"from_time"`
+	ToTime    sql.NullTime `json:"to_time"`
+	AfterID   int64        `json:"after_id"`
+	PageSize  int32        `json:"page_size"`
+}
+
+type ListAccountStatementRow struct {
+	ID                    int64         `json:"id"`
+	AccountID             int64         `json:"account_id"`
+	Amount                int64         `json:"amount"`
+	Kind                  string        `json:"kind"`
+	Reference             string        `json:"reference"`
+	Description           string        `json:"description"`
+	TransferID            sql.NullInt64 `json:"transfer_id"`
+	CounterpartyAccountID sql.NullInt64 `json:"counterparty_account_id"`
+	RunningBalance        int64         `json:"running_balance"`
+	CreatedAt             time.Time     `json:"created_at"`
+}
+
+func (q *Queries) ListAccountStatement(ctx context.Context, arg ListAccountStatementParams) ([]ListAccountStatementRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatement,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountStatementRow{}
+	for rows.Next() {
+		var i ListAccountStatementRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.Kind,
+			&i.Reference,
+			&i.Description,
+			&i.TransferID,
+			&i.CounterpartyAccountID,
+			&i.RunningBalance,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3
+SELECT id, account_id, amount, created_at, transfer_id, kind, reference, description, ledger_transaction_id FROM entries WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3
 `
 
 type ListEntriesParams struct {
@@ -70,6 +212,11 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.Kind,
+			&i.Reference,
+			&i.Description,
+			&i.LedgerTransactionID,
 		); err != nil {
 			return nil, err
 		}
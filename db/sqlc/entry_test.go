@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -10,9 +11,13 @@ import (
 )
 
 func createRandomEntry(t *testing.T, account Account) Entry {
+	journal := createRandomLedgerTransaction(t)
+
 	arg := CreateEntryParams{
-		AccountID: account.ID,
-		Amount:    utils.RandomMoney(),
+		AccountID:           account.ID,
+		Amount:              utils.RandomMoney(),
+		Kind:                EntryKindTransfer,
+		LedgerTransactionID: journal.ID,
 	}
 
 	entry, err := testQueries.CreateEntry(context.Background(), arg)
@@ -21,6 +26,8 @@ func createRandomEntry(t *testing.T, account Account) Entry {
 
 	require.Equal(t, arg.AccountID, entry.AccountID)
 	require.Equal(t, arg.Amount, entry.Amount)
+	require.Equal(t, arg.Kind, entry.Kind)
+	require.Equal(t, arg.LedgerTransactionID, entry.LedgerTransactionID)
 
 	require.NotZero(t, entry.ID)
 	require.NotZero(t, entry.CreatedAt)
@@ -67,3 +74,64 @@ func TestListEntries(t *testing.T) {
 		require.Equal(t, arg.AccountID, entry.AccountID)
 	}
 }
+
+func TestListAccountStatement(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, utils.USD)
+	account2 := createRandomAccountInCurrency(t, utils.USD)
+
+	_, err := store.DepositTx(context.Background(), CashTxParams{
+		AccountID: account1.ID,
+		Amount:    1000,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+	}
+
+	arg := ListAccountStatementParams{
+		AccountID: account1.ID,
+		PageSize:  3,
+	}
+
+	page1, err := testQueries.ListAccountStatement(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page1, 3)
+
+	deposit := page1[0]
+	require.Equal(t, EntryKindDeposit, deposit.Kind)
+	require.Equal(t, int64(1000), deposit.RunningBalance)
+	require.False(t, deposit.TransferID.Valid)
+	require.False(t, deposit.CounterpartyAccountID.Valid)
+
+	arg.AfterID = page1[len(page1)-1].ID
+	page2, err := testQueries.ListAccountStatement(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page2, 3)
+
+	// Running balances add up the entries before them, also on later pages.
+	balance := deposit.RunningBalance
+	for _, row := range append(page1[1:], page2...) {
+		balance += row.Amount
+		require.Equal(t, account1.ID, row.AccountID)
+		require.Equal(t, int64(-10), row.Amount)
+		require.Equal(t, balance, row.RunningBalance)
+		require.True(t, row.TransferID.Valid)
+		require.True(t, row.CounterpartyAccountID.Valid)
+		require.Equal(t, account2.ID, row.CounterpartyAccountID.Int64)
+	}
+	require.Equal(t, int64(950), balance)
+
+	arg.AfterID = 0
+	arg.FromTime = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	empty, err := testQueries.ListAccountStatement(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, empty)
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func createRandomIdempotencyKey(t *testing.T) IdempotencyKey {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	transfer := createRandomTransfer(t, account1, account2)
+
+	response, err := json.Marshal(TransferTxResult{Transfer: transfer})
+	require.NoError(t, err)
+
+	arg := CreateIdempotencyKeyParams{
+		Username:       account1.Owner,
+		IdempotencyKey: utils.RandomString(16),
+		RequestHash:    utils.RandomString(64),
+		TransferID:     transfer.ID,
+		Response:       response,
+	}
+
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, key)
+
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.IdempotencyKey, key.IdempotencyKey)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.Equal(t, arg.TransferID, key.TransferID)
+	require.JSONEq(t, string(arg.Response), string(key.Response))
+	require.NotZero(t, key.CreatedAt)
+
+	return key
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	createRandomIdempotencyKey(t)
+}
+
+func TestGetIdempotencyKey(t *testing.T) {
+	key1 := createRandomIdempotencyKey(t)
+
+	key2, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       key1.Username,
+		IdempotencyKey: key1.IdempotencyKey,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, key2)
+
+	require.Equal(t, key1.Username, key2.Username)
+	require.Equal(t, key1.IdempotencyKey, key2.IdempotencyKey)
+	require.Equal(t, key1.RequestHash, key2.RequestHash)
+	require.Equal(t, key1.TransferID, key2.TransferID)
+	require.WithinDuration(t, key1.CreatedAt, key2.CreatedAt, time.Second)
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func createRandomLedgerTransaction(t *testing.T) LedgerTransaction {
+	journal, err := testQueries.CreateLedgerTransaction(context.Background(), CreateLedgerTransactionParams{
+		Kind: EntryKindTransfer,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, journal.ID)
+	require.Equal(t, EntryKindTransfer, journal.Kind)
+	require.False(t, journal.TransferID.Valid)
+	require.NotZero(t, journal.CreatedAt)
+
+	return journal
+}
+
+func TestCreateLedgerTransaction(t *testing.T) {
+	createRandomLedgerTransaction(t)
+}
+
+func TestGetLedgerTransaction(t *testing.T) {
+	journal1 := createRandomLedgerTransaction(t)
+
+	journal2, err := testQueries.GetLedgerTransaction(context.Background(), journal1.ID)
+	require.NoError(t, err)
+	require.Equal(t, journal1, journal2)
+}
+
+func TestTransferTxLedgerTransaction(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 100)
+	account2 := createRandomAccountInCurrency(t, utils.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	journal := result.LedgerTransaction
+	require.Equal(t, EntryKindTransfer, journal.Kind)
+	require.Equal(t, result.Transfer.ID, journal.TransferID.Int64)
+
+	entries, err := testQueries.ListLedgerTransactionEntries(context.Background(), journal.ID)
+	require.NoError(t, err)
+	require.Equal(t, []Entry{result.FromEntry, result.ToEntry}, entries)
+}
+
+func TestListUnbalancedLedgerTransactions(t *testing.T) {
+	account := createRandomAccount(t)
+	journal := createRandomLedgerTransaction(t)
+
+	entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:           account.ID,
+		Amount:              utils.RandomInt(1, 1000),
+		Kind:                EntryKindTransfer,
+		LedgerTransactionID: journal.ID,
+	})
+	require.NoError(t, err)
+
+	rows, err := testQueries.ListUnbalancedLedgerTransactions(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, rows, ListUnbalancedLedgerTransactionsRow{
+		LedgerTransactionID: entry.LedgerTransactionID,
+		Currency:            account.Currency,
+		Total:               entry.Amount,
+	})
+}
+
+func TestListAccountBalanceMismatches(t *testing.T) {
+	store := NewStore(testDB)
+
+	// Balances written directly do not match the entries of the account.
+	account1 := createRandomAccountWithBalance(t, 100)
+	account2 := createRandomAccountInCurrency(t, utils.USD)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	rows, err := testQueries.ListAccountBalanceMismatches(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, rows, ListAccountBalanceMismatchesRow{
+		AccountID:    account1.ID,
+		Currency:     utils.USD,
+		Balance:      90,
+		EntriesTotal: -10,
+	})
+
+	for _, row := range rows {
+		require.NotEqual(t, account2.ID, row.AccountID)
+	}
+}
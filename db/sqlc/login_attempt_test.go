@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func createRandomLoginAttempt(t *testing.T, username string, clientIP string, outcome string) LoginAttempt {
+	arg := CreateLoginAttemptParams{
+		Username:  username,
+		ClientIp:  clientIP,
+		UserAgent: "go-test",
+		Outcome:   outcome,
+	}
+
+	attempt, err := testQueries.CreateLoginAttempt(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, attempt.ID)
+	require.Equal(t, arg.Username, attempt.Username)
+	require.Equal(t, arg.ClientIp, attempt.ClientIp)
+	require.Equal(t, arg.UserAgent, attempt.UserAgent)
+	require.Equal(t, arg.Outcome, attempt.Outcome)
+	require.NotZero(t, attempt.CreatedAt)
+
+	return attempt
+}
+
+func TestCreateLoginAttempt(t *testing.T) {
+	createRandomLoginAttempt(t, utils.RandomOwner(), "10.0.0.1", "throttled")
+
+	_, err := testQueries.CreateLoginAttempt(context.Background(), CreateLoginAttemptParams{
+		Username: utils.RandomOwner(),
+		ClientIp: "10.0.0.1",
+		Outcome:  "unknown",
+	})
+	require.Error(t, err)
+}
+
+func TestGetUsernameLoginFailures(t *testing.T) {
+	username := utils.RandomOwner()
+	clientIP := "10.1.0." + utils.RandomString(3)
+	since := time.Now().Add(-time.Hour)
+
+	arg := GetUsernameLoginFailuresParams{Username: username, Since: since}
+	failures, err := testQueries.GetUsernameLoginFailures(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, failures.Failures)
+
+	first := createRandomLoginAttempt(t, username, clientIP, "invalid_credentials")
+	createRandomLoginAttempt(t, username, clientIP, "throttled")
+	last := createRandomLoginAttempt(t, username, clientIP, "invalid_credentials")
+
+	failures, err = testQueries.GetUsernameLoginFailures(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), failures.Failures)
+	require.WithinDuration(t, first.CreatedAt, failures.FirstFailedAt, time.Millisecond)
+	require.WithinDuration(t, last.CreatedAt, failures.LastFailedAt, time.Millisecond)
+
+	// Failures older than since are not counted.
+	failures, err = testQueries.GetUsernameLoginFailures(context.Background(), GetUsernameLoginFailuresParams{
+		Username: username,
+		Since:    time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, failures.Failures)
+
+	// A successful login starts the count over.
+	createRandomLoginAttempt(t, username, clientIP, "success")
+
+	failures, err = testQueries.GetUsernameLoginFailures(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, failures.Failures)
+
+	createRandomLoginAttempt(t, username, clientIP, "invalid_credentials")
+
+	failures, err = testQueries.GetUsernameLoginFailures(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), failures.Failures)
+}
+
+func TestGetClientIPLoginFailures(t *testing.T) {
+	clientIP := "10.2.0." + utils.RandomString(3)
+	since := time.Now().Add(-time.Hour)
+
+	createRandomLoginAttempt(t, utils.RandomOwner(), clientIP, "invalid_credentials")
+	createRandomLoginAttempt(t, utils.RandomOwner(), clientIP, "invalid_credentials")
+
+	// Unlike the per-username count, a success from the same address does
+	// not reset it.
+	createRandomLoginAttempt(t, utils.RandomOwner(), clientIP, "success")
+
+	failures, err := testQueries.GetClientIPLoginFailures(context.Background(), GetClientIPLoginFailuresParams{
+		ClientIp: clientIP,
+		Since:    since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), failures.Failures)
+	require.False(t, failures.FirstFailedAt.After(failures.LastFailedAt))
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func createRandomMFAChallenge(t *testing.T, user User, expiresAt time.Time) MfaChallenge {
+	arg := CreateMFAChallengeParams{
+		Username:  user.Username,
+		TokenHash: utils.HashSecretCode(utils.RandomString(32)),
+		ExpiresAt: expiresAt,
+	}
+
+	challenge, err := testQueries.CreateMFAChallenge(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, challenge.Username)
+	require.Equal(t, arg.TokenHash, challenge.TokenHash)
+	require.Zero(t, challenge.Attempts)
+	require.False(t, challenge.UsedAt.Valid)
+
+	return challenge
+}
+
+func TestClaimMFAChallengeAttempt(t *testing.T) {
+	user := createRandomUser(t)
+	challenge := createRandomMFAChallenge(t, user, time.Now().Add(time.Minute))
+
+	arg := ClaimMFAChallengeAttemptParams{
+		TokenHash:   challenge.TokenHash,
+		MaxAttempts: 2,
+	}
+
+	for attempts := int32(1); attempts <= arg.MaxAttempts; attempts++ {
+		claimed, err := testQueries.ClaimMFAChallengeAttempt(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, challenge.ID, claimed.ID)
+		require.Equal(t, attempts, claimed.Attempts)
+	}
+
+	_, err := testQueries.ClaimMFAChallengeAttempt(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUseMFAChallenge(t *testing.T) {
+	user := createRandomUser(t)
+	challenge := createRandomMFAChallenge(t, user, time.Now().Add(time.Minute))
+
+	rows, err := testQueries.UseMFAChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rows, err = testQueries.UseMFAChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	// A used challenge accepts no more attempts.
+	_, err = testQueries.ClaimMFAChallengeAttempt(context.Background(), ClaimMFAChallengeAttemptParams{
+		TokenHash:   challenge.TokenHash,
+		MaxAttempts: 5,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestClaimExpiredMFAChallenge(t *testing.T) {
+	user := createRandomUser(t)
+	challenge := createRandomMFAChallenge(t, user, time.Now().Add(-time.Minute))
+
+	_, err := testQueries.ClaimMFAChallengeAttempt(context.Background(), ClaimMFAChallengeAttemptParams{
+		TokenHash:   challenge.TokenHash,
+		MaxAttempts: 5,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
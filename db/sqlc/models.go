@@ -5,7 +5,11 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
@@ -14,6 +18,67 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// only active accounts can send or receive money; closed ones are kept for their history
+	Status   string       `json:"status"`
+	ClosedAt sql.NullTime `json:"closed_at"`
+}
+
+type ApiKey struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	// public part of the key it is looked up by
+	Prefix string `json:"prefix"`
+	// sha256 of the secret part of the key
+	SecretHash string   `json:"secret_hash"`
+	Scopes     []string `json:"scopes"`
+	// keys without an expiry are valid until revoked
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type ApiKeyUsage struct {
+	ID        int64     `json:"id"`
+	ApiKeyID  int64     `json:"api_key_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// user the operation was made by, or system for background jobs
+	Actor        string `json:"actor"`
+	Action       string `json:"action"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	RequestID    string `json:"request_id"`
+	ClientIp     string `json:"client_ip"`
+	UserAgent    string `json:"user_agent"`
+	// json rather than jsonb, so that the text is kept exactly as hashed
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+	// hash of the previous event, or 64 zeros for the first one
+	PrevHash string `json:"prev_hash"`
+	// sha256 of prev_hash and the fields of this event
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code string `json:"code"`
+	// number of minor-unit digits, 2 for cents
+	Exponent  int32     `json:"exponent"`
+	Symbol    string    `json:"symbol"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -22,15 +87,161 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// set when the entry was written by a transfer
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// operation that wrote the entry
+	Kind string `json:"kind"`
+	// external reference of a deposit or withdrawal
+	Reference           string `json:"reference"`
+	Description         string `json:"description"`
+	LedgerTransactionID int64  `json:"ledger_transaction_id"`
+}
+
+type Hold struct {
+	ID          int64 `json:"id"`
+	AccountID   int64 `json:"account_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	// reserved on account_id until the hold is captured, released or expires
+	Amount    int64     `json:"amount"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// transfer the hold was captured by
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	SettledAt  sql.NullTime  `json:"settled_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username       string `json:"username"`
+	IdempotencyKey string `json:"idempotency_key"`
+	// sha256 of the original request body
+	RequestHash string `json:"request_hash"`
+	TransferID  int64  `json:"transfer_id"`
+	// result returned to the original request
+	Response  json.RawMessage `json:"response"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// journal grouping the entries of one money movement; its entries net to zero per currency
+type LedgerTransaction struct {
+	ID         int64         `json:"id"`
+	Kind       string        `json:"kind"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type LoginAttempt struct {
+	ID int64 `json:"id"`
+	// as typed by the client, whether or not such a user exists
+	Username  string    `json:"username"`
+	ClientIp  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	Outcome   string    `json:"outcome"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MfaChallenge struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// sha256 of the challenge token returned by the login
+	TokenHash string       `json:"token_hash"`
+	Attempts  int32        `json:"attempts"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type PasswordReset struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// sha256 of the code sent by email
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// bcrypt hash of the recovery code
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type RevokedToken struct {
+	// id of the revoked token payload
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64     `json:"id"`
+	Owner         string    `json:"owner"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	Frequency     string    `json:"frequency"`
+	StartAt       time.Time `json:"start_at"`
+	NextRunAt     time.Time `json:"next_run_at"`
+	Status        string    `json:"status"`
+	// number of completed runs; identifies the current run
+	Runs int32 `json:"runs"`
+	// failed attempts of the current run
+	Attempts       int32         `json:"attempts"`
+	LastError      string        `json:"last_error"`
+	LastRunAt      sql.NullTime  `json:"last_run_at"`
+	LastTransferID sql.NullInt64 `json:"last_transfer_id"`
+	// lease of the worker executing the current run
+	LockedUntil sql.NullTime `json:"locked_until"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type Session struct {
+	// id of the refresh token payload
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type TotpSecret struct {
+	Username string `json:"username"`
+	Secret   string `json:"secret"`
+	// two-factor authentication is enabled once the secret is confirmed
+	ConfirmedAt sql.NullTime `json:"confirmed_at"`
+	// time step of the last accepted code, so that no code is accepted twice
+	LastUsedStep int64     `json:"last_used_step"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// must be positive
-	Amount    int64     `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	// must be positive, debited from the source account in from_currency
+	Amount       int64     `json:"amount"`
+	CreatedAt    time.Time `json:"created_at"`
+	FromCurrency string    `json:"from_currency"`
+	// credited to the destination account, in to_currency
+	ToAmount   int64  `json:"to_amount"`
+	ToCurrency string `json:"to_currency"`
+	// units of to_currency bought by one unit of from_currency
+	ExchangeRate string `json:"exchange_rate"`
+	// when the exchange rate was published
+	RateUpdatedAt time.Time `json:"rate_updated_at"`
+	// transfer this one refunds, if it is a reversal
+	ReversalOf sql.NullInt64 `json:"reversal_of"`
+	// part of to_amount refunded by reversals so far
+	ReversedAmount int64 `json:"reversed_amount"`
 }
 
 type User struct {
@@ -40,4 +251,20 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// tokens issued before this instant are rejected
+	TokensRevokedAt time.Time `json:"tokens_revoked_at"`
+	Role            string    `json:"role"`
+	IsEmailVerified bool      `json:"is_email_verified"`
+}
+
+type VerifyEmail struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// address the code was sent to, verified only while it is still the user's email
+	Email string `json:"email"`
+	// sha256 of the code sent by email
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	CreatedAt time.Time    `json:"created_at"`
 }
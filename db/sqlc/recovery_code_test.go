@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestUseRecoveryCode(t *testing.T) {
+	user := createRandomUser(t)
+
+	code, err := testQueries.CreateRecoveryCode(context.Background(), CreateRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: utils.RandomString(60),
+	})
+	require.NoError(t, err)
+	require.False(t, code.UsedAt.Valid)
+
+	codes, err := testQueries.ListUnusedRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []RecoveryCode{code}, codes)
+
+	rows, err := testQueries.UseRecoveryCode(context.Background(), code.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// Recovery codes are single-use.
+	rows, err = testQueries.UseRecoveryCode(context.Background(), code.ID)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	codes, err = testQueries.ListUnusedRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, codes)
+}
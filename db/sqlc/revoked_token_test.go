@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTokenRevoked(t *testing.T) {
+	user := createRandomUser(t)
+	tokenID := uuid.New()
+	issuedAt := time.Now()
+
+	arg := IsTokenRevokedParams{
+		ID:       tokenID,
+		Username: user.Username,
+		IssuedAt: issuedAt,
+	}
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	err = testQueries.CreateRevokedToken(context.Background(), CreateRevokedTokenParams{
+		ID:        tokenID,
+		Username:  user.Username,
+		ExpiresAt: issuedAt.Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// Revoking the same token twice is a no-op.
+	err = testQueries.CreateRevokedToken(context.Background(), CreateRevokedTokenParams{
+		ID:        tokenID,
+		Username:  user.Username,
+		ExpiresAt: issuedAt.Add(time.Minute),
+	})
+	require.NoError(t, err)
+}
+
+func TestRevokeUserTokens(t *testing.T) {
+	user := createRandomUser(t)
+	revokedAt := time.Now().Truncate(time.Second).Add(100 * time.Millisecond)
+
+	err := testQueries.RevokeUserTokens(context.Background(), RevokeUserTokensParams{
+		RevokedAt: revokedAt,
+		Username:  user.Username,
+	})
+	require.NoError(t, err)
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: user.Username,
+		IssuedAt: revokedAt.Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// Within the second of the revocation, only the tokens issued before
+	// it are revoked.
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: user.Username,
+		IssuedAt: revokedAt.Add(-50 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: user.Username,
+		IssuedAt: revokedAt.Add(500 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: user.Username,
+		IssuedAt: revokedAt.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestDeleteExpiredRevokedTokens(t *testing.T) {
+	user := createRandomUser(t)
+	tokenID := uuid.New()
+
+	err := testQueries.CreateRevokedToken(context.Background(), CreateRevokedTokenParams{
+		ID:        tokenID,
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	rows, err := testQueries.DeleteExpiredRevokedTokens(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, rows, int64(1))
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       tokenID,
+		Username: user.Username,
+		IssuedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func createRandomScheduledTransfer(t *testing.T, startAt time.Time) ScheduledTransfer {
+	account1 := createRandomAccountInCurrency(t, utils.USD)
+	account2 := createRandomAccountInCurrency(t, utils.USD)
+
+	arg := CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        utils.RandomInt(1, 1000),
+		Currency:      utils.USD,
+		Frequency:     "daily",
+		StartAt:       startAt,
+	}
+
+	schedule, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, schedule)
+
+	require.Equal(t, arg.Owner, schedule.Owner)
+	require.Equal(t, arg.FromAccountID, schedule.FromAccountID)
+	require.Equal(t, arg.ToAccountID, schedule.ToAccountID)
+	require.Equal(t, arg.Amount, schedule.Amount)
+	require.Equal(t, arg.Frequency, schedule.Frequency)
+	require.WithinDuration(t, arg.StartAt, schedule.StartAt, time.Second)
+	require.WithinDuration(t, arg.StartAt, schedule.NextRunAt, time.Second)
+	require.Equal(t, "active", schedule.Status)
+	require.Zero(t, schedule.Runs)
+	require.False(t, schedule.LockedUntil.Valid)
+
+	return schedule
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+}
+
+func TestUpdateScheduledTransfer(t *testing.T) {
+	schedule1 := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	schedule2, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     schedule1.ID,
+		Status: sql.NullString{String: "paused", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "paused", schedule2.Status)
+	require.Equal(t, schedule1.Amount, schedule2.Amount)
+	require.WithinDuration(t, schedule1.NextRunAt, schedule2.NextRunAt, time.Second)
+}
+
+func TestDeleteScheduledTransfer(t *testing.T) {
+	schedule1 := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	err := testQueries.DeleteScheduledTransfer(context.Background(), schedule1.ID)
+	require.NoError(t, err)
+
+	schedule2, err := testQueries.GetScheduledTransfer(context.Background(), schedule1.ID)
+	require.Error(t, err)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+	require.Empty(t, schedule2)
+}
+
+func TestClaimDueScheduledTransfers(t *testing.T) {
+	due := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+	notDue := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	now := time.Now()
+	arg := ClaimDueScheduledTransfersParams{
+		LockedUntil: sql.NullTime{Time: now.Add(time.Minute), Valid: true},
+		Now:         now,
+		BatchSize:   1000,
+	}
+
+	claimed, err := testQueries.ClaimDueScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+
+	ids := make(map[int64]bool, len(claimed))
+	for _, schedule := range claimed {
+		ids[schedule.ID] = true
+	}
+	require.True(t, ids[due.ID])
+	require.False(t, ids[notDue.ID])
+
+	// A leased schedule is not claimed again until the lease expires.
+	claimed, err = testQueries.ClaimDueScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	for _, schedule := range claimed {
+		require.NotEqual(t, due.ID, schedule.ID)
+	}
+}
+
+func TestRecordScheduledTransferOutcome(t *testing.T) {
+	schedule := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+
+	rows, err := testQueries.RecordScheduledTransferFailure(context.Background(), RecordScheduledTransferFailureParams{
+		LastError: "insufficient funds",
+		NextRunAt: time.Now().Add(time.Minute),
+		Status:    "active",
+		ID:        schedule.ID,
+		Runs:      schedule.Runs,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	nextRunAt := time.Now().Add(24 * time.Hour)
+	rows, err = testQueries.RecordScheduledTransferSuccess(context.Background(), RecordScheduledTransferSuccessParams{
+		NextRunAt: nextRunAt,
+		Status:    "active",
+		ID:        schedule.ID,
+		Runs:      schedule.Runs,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	updated, err := testQueries.GetScheduledTransfer(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, schedule.Runs+1, updated.Runs)
+	require.Zero(t, updated.Attempts)
+	require.Empty(t, updated.LastError)
+	require.True(t, updated.LastRunAt.Valid)
+	require.WithinDuration(t, nextRunAt, updated.NextRunAt, time.Second)
+
+	// An outcome recorded for a run that already finished is ignored.
+	rows, err = testQueries.RecordScheduledTransferSuccess(context.Background(), RecordScheduledTransferSuccessParams{
+		NextRunAt: nextRunAt,
+		Status:    "active",
+		ID:        schedule.ID,
+		Runs:      schedule.Runs,
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
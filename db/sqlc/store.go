@@ -3,12 +3,49 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrInsufficientFunds is returned when a transfer would take the source
+	// account below its overdraft limit.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrIdempotencyKeyConflict is returned when an idempotency key is reused
+	// with a request that differs from the one it was first used with.
+	ErrIdempotencyKeyConflict = errors.New("idempotency key already used with a different request")
+	// ErrAccountFrozen is returned when either side of a transfer is frozen.
+	ErrAccountFrozen = errors.New("account is frozen")
+	// ErrAccountClosed is returned when either side of a transfer is closed.
+	ErrAccountClosed = errors.New("account is closed")
+	// ErrExchangeRateRequired is returned when the accounts of a transfer
+	// hold different currencies but no converted amount was given.
+	ErrExchangeRateRequired = errors.New("exchange rate is required for cross-currency transfers")
 )
 
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	LogoutTx(ctx context.Context, arg LogoutTxParams) error
+	DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	ReconcileAccountTx(ctx context.Context, arg ReconcileAccountTxParams) (ReconcileAccountTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	PlaceHold(ctx context.Context, arg PlaceHoldParams) (Hold, error)
+	CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error)
+	ReleaseHold(ctx context.Context, holdID int64) (Hold, error)
+	UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusTxParams) (Account, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
+	VerifyEmailTx(ctx context.Context, codeHash string) (User, error)
+	ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (TotpSecret, error)
+	RunScheduledTransferTx(ctx context.Context, arg RunScheduledTransferTxParams) (RunScheduledTransferTxResult, error)
 }
 
 type SQLStore struct {
@@ -45,6 +82,23 @@ type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// ToAmount, ExchangeRate and RateUpdatedAt describe the credited leg of
+	// a cross-currency transfer. They are ignored, and ToAmount is taken to
+	// be Amount, when both accounts hold the same currency.
+	ToAmount      int64     `json:"to_amount"`
+	ExchangeRate  string    `json:"exchange_rate"`
+	RateUpdatedAt time.Time `json:"rate_updated_at"`
+	// Idempotency makes the transfer safe to retry. Leave nil to always
+	// create a new transfer.
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+// IdempotencyParams identifies a client request so that retries of it
+// return the original result instead of moving money again.
+type IdempotencyParams struct {
+	Username    string
+	Key         string
+	RequestHash string
 }
 
 type TransferTxResult struct {
@@ -53,6 +107,14 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// LedgerTransaction is the journal every entry of the transfer belongs to.
+	LedgerTransaction LedgerTransaction `json:"ledger_transaction"`
+	// ExchangeEntries are the legs booked on the cash accounts of a
+	// cross-currency transfer.
+	ExchangeEntries []Entry `json:"exchange_entries,omitempty"`
+	// Replayed is set when the result was loaded from an earlier request
+	// with the same idempotency key.
+	Replayed bool `json:"-"`
 }
 
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
@@ -60,57 +122,352 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
-
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
-
-		if err != nil {
+		result, err = transfer(ctx, q, arg)
+		if err != nil || result.Replayed {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "transfer.create",
+			ResourceType: AuditResourceTransfer,
+			ResourceID:   auditID(result.Transfer.ID),
+			After:        result,
 		})
+	})
 
-		if err != nil {
-			return err
+	if err != nil && arg.Idempotency != nil && isIdempotencyKeyViolation(err) {
+		// A concurrent request with the same key committed first and this
+		// transaction has been rolled back; answer with its result instead.
+		key, getErr := store.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+			Username:       arg.Idempotency.Username,
+			IdempotencyKey: arg.Idempotency.Key,
+		})
+		if getErr != nil {
+			return result, getErr
 		}
+		return replayTransfer(key, arg.Idempotency)
+	}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+	return result, err
+}
+
+// transfer moves money between two accounts in the caller's transaction.
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	accounts, err := lockTransferAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	// A retry of a committed request blocks on the account locks above
+	// until the original commits, so the key is visible by now.
+	if arg.Idempotency != nil {
+		key, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+			Username:       arg.Idempotency.Username,
+			IdempotencyKey: arg.Idempotency.Key,
 		})
+		if err == nil {
+			return replayTransfer(key, arg.Idempotency)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return result, err
+		}
+	}
+
+	if err := checkActive(accounts.from, accounts.to); err != nil {
+		return result, err
+	}
+
+	spendable, err := spendableBalance(ctx, q, accounts.from)
+	if err != nil {
+		return result, err
+	}
+
+	if spendable < arg.Amount {
+		return result, ErrInsufficientFunds
+	}
+
+	toAmount, exchangeRate, rateUpdatedAt := arg.ToAmount, arg.ExchangeRate, arg.RateUpdatedAt
+	if !accounts.crossCurrency() {
+		toAmount, exchangeRate, rateUpdatedAt = arg.Amount, "1", time.Now()
+	} else if toAmount <= 0 || exchangeRate == "" || rateUpdatedAt.IsZero() {
+		return result, ErrExchangeRateRequired
+	}
+
+	result, err = bookTransfer(ctx, q, EntryKindTransfer, accounts, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		FromCurrency:  accounts.from.Currency,
+		ToAmount:      toAmount,
+		ToCurrency:    accounts.to.Currency,
+		ExchangeRate:  exchangeRate,
+		RateUpdatedAt: rateUpdatedAt,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if arg.Idempotency != nil {
+		return result, saveIdempotencyKey(ctx, q, arg.Idempotency, result)
+	}
+
+	return result, nil
+}
+
+// saveIdempotencyKey stores the result of a transfer under its idempotency
+// key. It must run in the same transaction as the transfer itself.
+func saveIdempotencyKey(ctx context.Context, q *Queries, idempotency *IdempotencyParams, result TransferTxResult) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:       idempotency.Username,
+		IdempotencyKey: idempotency.Key,
+		RequestHash:    idempotency.RequestHash,
+		TransferID:     result.Transfer.ID,
+		Response:       response,
+	})
+	return err
+}
+
+func replayTransfer(key IdempotencyKey, idempotency *IdempotencyParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	if key.RequestHash != idempotency.RequestHash {
+		return result, ErrIdempotencyKeyConflict
+	}
+
+	if err := json.Unmarshal(key.Response, &result); err != nil {
+		return result, err
+	}
+
+	result.Replayed = true
+	return result, nil
+}
+
+func isIdempotencyKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == "idempotency_keys_pkey"
+}
+
+// transferAccounts are the locked accounts of a transfer, along with the
+// cash accounts that carry the exchange legs when it is cross-currency.
+type transferAccounts struct {
+	from     Account
+	to       Account
+	fromCash Account
+	toCash   Account
+}
+
+func (a transferAccounts) crossCurrency() bool {
+	return a.from.Currency != a.to.Currency
+}
+
+// lockTransferAccounts locks both accounts of a transfer and, when they hold
+// different currencies, the cash accounts of both currencies.
+func lockTransferAccounts(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64) (transferAccounts, error) {
+	var accounts transferAccounts
+
+	// Owners and currencies never change, so they can be read before
+	// locking to find out which cash accounts carry the exchange legs.
+	from, err := q.GetAccount(ctx, fromAccountID)
+	if err != nil {
+		return accounts, err
+	}
+
+	to, err := q.GetAccount(ctx, toAccountID)
+	if err != nil {
+		return accounts, err
+	}
+
+	if from.Owner == SystemUsername || to.Owner == SystemUsername {
+		return accounts, ErrSystemAccount
+	}
+
+	ids := []int64{from.ID, to.ID}
 
+	if from.Currency != to.Currency {
+		accounts.fromCash, err = findCashAccount(ctx, q, from.Currency)
 		if err != nil {
-			return err
+			return accounts, err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(
-				ctx,
-				q,
-				arg.FromAccountID,
-				-arg.Amount,
-				arg.ToAccountID,
-				arg.Amount,
-			)
-
-			return err
+		accounts.toCash, err = findCashAccount(ctx, q, to.Currency)
+		if err != nil {
+			return accounts, err
 		}
 
+		ids = append(ids, accounts.fromCash.ID, accounts.toCash.ID)
+	}
+
+	locked, err := lockAccounts(ctx, q, ids...)
+	if err != nil {
+		return accounts, err
+	}
+
+	accounts.from, accounts.to = locked[from.ID], locked[to.ID]
+	return accounts, nil
+}
+
+// bookTransfer records a transfer between locked accounts: the transfer
+// itself, its journal, the entries and balances of both accounts, and the
+// exchange legs of a cross-currency transfer.
+func bookTransfer(ctx context.Context, q *Queries, kind string, accounts transferAccounts, arg CreateTransferParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	transfer, err := q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+	result.Transfer = transfer
+
+	transferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+
+	result.LedgerTransaction, err = q.CreateLedgerTransaction(ctx, CreateLedgerTransactionParams{
+		Kind:       kind,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:           arg.FromAccountID,
+		Amount:              -arg.Amount,
+		TransferID:          transferID,
+		Kind:                kind,
+		LedgerTransactionID: result.LedgerTransaction.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:           arg.ToAccountID,
+		Amount:              arg.ToAmount,
+		TransferID:          transferID,
+		Kind:                kind,
+		LedgerTransactionID: result.LedgerTransaction.ID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(
+			ctx,
+			q,
+			arg.FromAccountID,
+			-arg.Amount,
+			arg.ToAccountID,
+			arg.ToAmount,
+		)
+	} else {
 		result.ToAccount, result.FromAccount, err = addMoney(
 			ctx,
 			q,
 			arg.ToAccountID,
-			arg.Amount,
+			arg.ToAmount,
 			arg.FromAccountID,
 			-arg.Amount,
 		)
+	}
 
-		return err
-	})
+	if err != nil {
+		return result, err
+	}
 
-	return result, err
+	// The bank buys the debited currency and sells the credited one
+	// through its cash accounts, so the journal nets to zero in each.
+	if accounts.crossCurrency() {
+		result.ExchangeEntries, err = bookExchange(ctx, q, result.LedgerTransaction,
+			accounts.fromCash.ID, arg.Amount,
+			accounts.toCash.ID, -arg.ToAmount,
+		)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// spendableBalance is how much can still be taken from a locked account:
+// its balance and overdraft, less the funds reserved by its active holds.
+func spendableBalance(ctx context.Context, q *Queries, account Account) (int64, error) {
+	held, err := q.GetAccountHeldAmount(ctx, account.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return account.Balance + account.OverdraftLimit - held, nil
+}
+
+// lockAccounts takes a row lock on every given account, always in ascending
+// id order so that concurrent operations on overlapping accounts cannot
+// deadlock.
+func lockAccounts(ctx context.Context, q *Queries, accountIDs ...int64) (map[int64]Account, error) {
+	ids := append([]int64(nil), accountIDs...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		if _, locked := accounts[id]; locked {
+			continue
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
+
+// bookExchange writes the exchange legs of a cross-currency transfer on the
+// cash accounts of both currencies, in the transfer's journal.
+func bookExchange(ctx context.Context, q *Queries, journal LedgerTransaction, accountID1 int64, amount1 int64, accountID2 int64, amount2 int64) ([]Entry, error) {
+	entries := make([]Entry, 0, 2)
+
+	for _, leg := range []struct {
+		accountID int64
+		amount    int64
+	}{
+		{accountID1, amount1},
+		{accountID2, amount2},
+	} {
+		entry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:           leg.accountID,
+			Amount:              leg.amount,
+			TransferID:          journal.TransferID,
+			Kind:                journal.Kind,
+			LedgerTransactionID: journal.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:    leg.accountID,
+			Amout: leg.amount,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
 }
 
 func addMoney(ctx context.Context, q *Queries, accountID1 int64, amount1 int64, accountID2 int64, amount2 int64) (account1 Account, account2 Account, err error) {
@@ -3,15 +3,31 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
 )
 
+// createRandomAccountWithBalance creates a USD account, so that money can
+// move between any two of them without an exchange rate.
+func createRandomAccountWithBalance(t *testing.T, balance int64) Account {
+	account := createRandomAccountInCurrency(t, utils.USD)
+
+	account, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: balance,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountInCurrency(t, utils.USD)
 
 	n := 5
 	amount := int64(10)
@@ -102,8 +118,8 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 1000)
 
 	n := 10
 	amount := int64(10)
@@ -143,4 +159,189 @@ func TestTransferTxDeadlock(t *testing.T) {
 
 	require.Equal(t, account1.Balance, updateAccount1.Balance)
 	require.Equal(t, account2.Balance, updateAccount2.Balance)
-}
\ No newline at end of file
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 10)
+	account2 := createRandomAccountInCurrency(t, utils.USD)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        11,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updateAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updateAccount1.Balance)
+
+	_, err = testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 5,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        15,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-5), result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxCrossCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountInCurrency(t, utils.EUR)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.ErrorIs(t, err, ErrExchangeRateRequired)
+
+	rateUpdatedAt := time.Now().Add(-time.Hour)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		ToAmount:      92,
+		ExchangeRate:  "0.92",
+		RateUpdatedAt: rateUpdatedAt,
+	})
+	require.NoError(t, err)
+
+	transfer := result.Transfer
+	require.Equal(t, int64(100), transfer.Amount)
+	require.Equal(t, utils.USD, transfer.FromCurrency)
+	require.Equal(t, int64(92), transfer.ToAmount)
+	require.Equal(t, utils.EUR, transfer.ToCurrency)
+	require.Equal(t, "0.9200000000", transfer.ExchangeRate)
+	require.WithinDuration(t, rateUpdatedAt, transfer.RateUpdatedAt, time.Second)
+
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(92), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+92, result.ToAccount.Balance)
+
+	// The exchange legs on the cash accounts balance the journal in each
+	// currency.
+	require.Len(t, result.ExchangeEntries, 2)
+	require.Equal(t, int64(100), result.ExchangeEntries[0].Amount)
+	require.Equal(t, int64(-92), result.ExchangeEntries[1].Amount)
+
+	entries, err := testQueries.ListLedgerTransactionEntries(context.Background(), result.LedgerTransaction.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	totals := make(map[string]int64)
+	for _, entry := range entries {
+		account, err := testQueries.GetAccount(context.Background(), entry.AccountID)
+		require.NoError(t, err)
+		totals[account.Currency] += entry.Amount
+	}
+	require.Equal(t, map[string]int64{utils.USD: 0, utils.EUR: 0}, totals)
+}
+
+func TestTransferTxFrozenAccount(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountInCurrency(t, utils.USD)
+
+	_, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status: AccountStatusFrozen,
+		ID:     account2.ID,
+	})
+	require.NoError(t, err)
+
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10},
+	} {
+		_, err = store.TransferTx(context.Background(), arg)
+		require.ErrorIs(t, err, ErrAccountFrozen)
+	}
+
+	updateAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updateAccount1.Balance)
+}
+
+func TestTransferTxIdempotent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountInCurrency(t, utils.USD)
+
+	n := 5
+	amount := int64(10)
+	idempotency := &IdempotencyParams{
+		Username:    account1.Owner,
+		Key:         utils.RandomString(16),
+		RequestHash: utils.RandomString(64),
+	}
+
+	errs := make(chan error)
+	results := make(chan TransferTxResult)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Idempotency:   idempotency,
+			})
+			errs <- err
+			results <- result
+		}()
+	}
+
+	var transferID int64
+	replayed := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+
+		result := <-results
+		if transferID == 0 {
+			transferID = result.Transfer.ID
+		}
+		require.Equal(t, transferID, result.Transfer.ID)
+
+		if result.Replayed {
+			replayed++
+		}
+	}
+	require.Equal(t, n-1, replayed)
+
+	updateAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-amount, updateAccount1.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount + 1,
+		Idempotency: &IdempotencyParams{
+			Username:    idempotency.Username,
+			Key:         idempotency.Key,
+			RequestHash: utils.RandomString(64),
+		},
+	})
+	require.ErrorIs(t, err, ErrIdempotencyKeyConflict)
+}
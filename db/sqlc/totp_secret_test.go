@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func createRandomTOTPSecret(t *testing.T, user User) TotpSecret {
+	arg := UpsertTOTPSecretParams{
+		Username: user.Username,
+		Secret:   utils.RandomString(32),
+	}
+
+	secret, err := testQueries.UpsertTOTPSecret(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, secret.Username)
+	require.Equal(t, arg.Secret, secret.Secret)
+	require.False(t, secret.ConfirmedAt.Valid)
+	require.Zero(t, secret.LastUsedStep)
+
+	return secret
+}
+
+func TestUpsertTOTPSecret(t *testing.T) {
+	user := createRandomUser(t)
+	secret1 := createRandomTOTPSecret(t, user)
+
+	// Enrolling again replaces a secret that is not confirmed yet.
+	secret2 := createRandomTOTPSecret(t, user)
+	require.NotEqual(t, secret1.Secret, secret2.Secret)
+
+	secret3, err := testQueries.GetTOTPSecret(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, secret2.Secret, secret3.Secret)
+
+	_, err = testQueries.ConfirmTOTPSecret(context.Background(), ConfirmTOTPSecretParams{
+		Step:     100,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+
+	// A confirmed secret is kept.
+	_, err = testQueries.UpsertTOTPSecret(context.Background(), UpsertTOTPSecretParams{
+		Username: user.Username,
+		Secret:   utils.RandomString(32),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	secret4, err := testQueries.GetTOTPSecret(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, secret2.Secret, secret4.Secret)
+	require.True(t, secret4.ConfirmedAt.Valid)
+}
+
+func TestUseTOTPStep(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomTOTPSecret(t, user)
+
+	arg := UseTOTPStepParams{Step: 100, Username: user.Username}
+
+	// Codes of an unconfirmed secret log nobody in.
+	rows, err := testQueries.UseTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	secret, err := testQueries.ConfirmTOTPSecret(context.Background(), ConfirmTOTPSecretParams{
+		Step:     99,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(99), secret.LastUsedStep)
+
+	rows, err = testQueries.UseTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// Neither the same step nor an earlier one is accepted again.
+	rows, err = testQueries.UseTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	rows, err = testQueries.UseTOTPStep(context.Background(), UseTOTPStepParams{Step: 99, Username: user.Username})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
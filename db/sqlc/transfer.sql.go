@@ -7,20 +7,50 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount) VALUES ($1, $2, $3) RETURNING id, from_account_id, to_account_id, amount, created_at
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    from_currency,
+    to_amount,
+    to_currency,
+    exchange_rate,
+    rate_updated_at,
+    reversal_of
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, from_account_id, to_account_id, amount, created_at, from_currency, to_amount, to_currency, exchange_rate, rate_updated_at, reversal_of, reversed_amount
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	FromCurrency  string        `json:"from_currency"`
+	ToAmount      int64         `json:"to_amount"`
+	ToCurrency    string        `json:"to_currency"`
+	ExchangeRate  string        `json:"exchange_rate"`
+	RateUpdatedAt time.Time     `json:"rate_updated_at"`
+	ReversalOf    sql.NullInt64 `json:"reversal_of"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.FromCurrency,
+		arg.ToAmount,
+		arg.ToCurrency,
+		arg.ExchangeRate,
+		arg.RateUpdatedAt,
+		arg.ReversalOf,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -28,12 +58,19 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.FromCurrency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.RateUpdatedAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers WHERE id = $1 LIMIT 1
+SELECT id, from_account_id, to_account_id, amount, created_at, from_currency, to_amount, to_currency, exchange_rate, rate_updated_at, reversal_of, reversed_amount FROM transfers WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
@@ -45,27 +82,198 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.FromCurrency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.RateUpdatedAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, from_currency, to_amount, to_currency, exchange_rate, rate_updated_at, reversal_of, reversed_amount FROM transfers WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.FromCurrency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.RateUpdatedAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+	)
+	return i, err
+}
+
+const addTransferReversedAmount = `-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, from_currency, to_amount, to_currency, exchange_rate, rate_updated_at, reversal_of, reversed_amount
+`
+
+type AddTransferReversedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, addTransferReversedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.FromCurrency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.RateUpdatedAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers WHERE from_account_id = $1 OR to_account_id = $2 ORDER BY id LIMIT $3 OFFSET $4
+SELECT id, from_account_id, to_account_id, amount, created_at, from_currency, to_amount, to_currency, exchange_rate, rate_updated_at, reversal_of, reversed_amount FROM transfers
+WHERE (from_account_id = $1 OR to_account_id = $1)
+  AND ($2::varchar IS NULL
+    OR ($2 = 'out' AND from_account_id = $1)
+    OR ($2 = 'in' AND to_account_id = $1))
+  AND ($3::bigint IS NULL
+    OR (from_account_id = $1 AND to_account_id = $3)
+    OR (to_account_id = $1 AND from_account_id = $3))
+  AND ($4::bigint IS NULL OR amount >= $4)
+  AND ($5::bigint IS NULL OR amount <= $5)
+  AND ($6::timestamptz IS NULL OR created_at >= $6)
+  AND ($7::timestamptz IS NULL OR created_at < $7)
+  AND ($8::timestamptz IS NULL
+    OR (created_at, id) < ($8, $9::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT $10
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	AccountID       int64          `json:"account_id"`
+	Direction       sql.NullString `json:"direction"`
+	CounterpartyID  sql.NullInt64  `json:"counterparty_id"`
+	MinAmount       sql.NullInt64  `json:"min_amount"`
+	MaxAmount       sql.NullInt64  `json:"max_amount"`
+	FromTime        sql.NullTime   `json:"from_time"`
+	ToTime          sql.NullTime   `json:"to_time"`
+	CursorCreatedAt sql.NullTime   `json:"cursor_created_at"`
+	CursorID        sql.NullInt64  `json:"cursor_id"`
+	PageSize        int32          `json:"page_size"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
 	rows, err := q.db.QueryContext(ctx, listTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
-		arg.Limit,
-		arg.Offset,
+		arg.AccountID,
+		arg.Direction,
+		arg.CounterpartyID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.FromCurrency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.ExchangeRate,
+			&i.RateUpdatedAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransfers = `-- name: ListUserTransfers :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.from_currency, t.to_amount, t.to_currency, t.exchange_rate, t.rate_updated_at, t.reversal_of, t.reversed_amount FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (fa.owner = $1 OR ta.owner = $1)
+  AND ($2::varchar IS NULL
+    OR ($2 = 'out' AND fa.owner = $1)
+    OR ($2 = 'in' AND ta.owner = $1))
+  AND ($3::bigint IS NULL
+    OR (fa.owner = $1 AND t.to_account_id = $3)
+    OR (ta.owner = $1 AND t.from_account_id = $3))
+  AND ($4::bigint IS NULL OR t.amount >= $4)
+  AND ($5::bigint IS NULL OR t.amount <= $5)
+  AND ($6::timestamptz IS NULL OR t.created_at >= $6)
+  AND ($7::timestamptz IS NULL OR t.created_at < $7)
+  AND ($8::timestamptz IS NULL
+    OR (t.created_at, t.id) < ($8, $9::bigint))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $10
+`
+
+type ListUserTransfersParams struct {
+	Owner           string         `json:"owner"`
+	Direction       sql.NullString `json:"direction"`
+	CounterpartyID  sql.NullInt64  `json:"counterparty_id"`
+	MinAmount       sql.NullInt64  `json:"min_amount"`
+	MaxAmount       sql.NullInt64  `json:"max_amount"`
+	FromTime        sql.NullTime   `json:"from_time"`
+	ToTime          sql.NullTime   `json:"to_time"`
+	CursorCreatedAt sql.NullTime   `json:"cursor_created_at"`
+	CursorID        sql.NullInt64  `json:"cursor_id"`
+	PageSize        int32          `json:"page_size"`
+}
+
+func (q *Queries) ListUserTransfers(ctx context.Context, arg ListUserTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransfers,
+		arg.Owner,
+		arg.Direction,
+		arg.CounterpartyID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
 	)
 	if err != nil {
 		return nil, err
@@ -80,6 +288,13 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.FromCurrency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.ExchangeRate,
+			&i.RateUpdatedAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
 		); err != nil {
 			return nil, err
 		}
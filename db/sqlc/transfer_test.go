@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -10,10 +11,16 @@ import (
 )
 
 func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
+	amount := utils.RandomMoney()
 	arg := CreateTransferParams{
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
-		Amount:        utils.RandomMoney(),
+		Amount:        amount,
+		FromCurrency:  account1.Currency,
+		ToAmount:      amount,
+		ToCurrency:    account2.Currency,
+		ExchangeRate:  "1",
+		RateUpdatedAt: time.Now(),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -23,6 +30,11 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.FromCurrency, transfer.FromCurrency)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
+	require.Equal(t, arg.ToCurrency, transfer.ToCurrency)
+	require.Equal(t, "1.0000000000", transfer.ExchangeRate)
+	require.WithinDuration(t, arg.RateUpdatedAt, transfer.RateUpdatedAt, time.Second)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -61,10 +73,8 @@ func TestListTransfer(t *testing.T) {
 	}
 
 	arg := ListTransfersParams{
-		FromAccountID: account1.ID,
-		ToAccountID:   account1.ID,
-		Limit:         5,
-		Offset:        5,
+		AccountID: account1.ID,
+		PageSize:  5,
 	}
 
 	transfers, err := testQueries.ListTransfers(context.Background(), arg)
@@ -75,4 +85,84 @@ func TestListTransfer(t *testing.T) {
 		require.NotEmpty(t, transfer)
 		require.True(t, transfer.FromAccountID == account1.ID || transfer.ToAccountID == account1.ID)
 	}
+
+	last := transfers[len(transfers)-1]
+	arg.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+	arg.CursorID = sql.NullInt64{Int64: last.ID, Valid: true}
+
+	nextTransfers, err := testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, nextTransfers, 5)
+
+	for _, transfer := range nextTransfers {
+		require.NotContains(t, transfers, transfer)
+		require.True(t, transfer.CreatedAt.Before(last.CreatedAt) ||
+			(transfer.CreatedAt.Equal(last.CreatedAt) && transfer.ID < last.ID))
+	}
+}
+
+func TestListTransfersFilters(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		createRandomTransfer(t, account1, account2)
+		createRandomTransfer(t, account2, account1)
+		createRandomTransfer(t, account3, account1)
+	}
+
+	arg := ListTransfersParams{
+		AccountID: account1.ID,
+		Direction: sql.NullString{String: "in", Valid: true},
+		PageSize:  10,
+	}
+
+	transfers, err := testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 6)
+	for _, transfer := range transfers {
+		require.Equal(t, account1.ID, transfer.ToAccountID)
+	}
+
+	arg.CounterpartyID = sql.NullInt64{Int64: account3.ID, Valid: true}
+	transfers, err = testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+	for _, transfer := range transfers {
+		require.Equal(t, account3.ID, transfer.FromAccountID)
+	}
+
+	arg.Direction = sql.NullString{String: "out", Valid: true}
+	transfers, err = testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestListUserTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		createRandomTransfer(t, account1, account2)
+		createRandomTransfer(t, account2, account1)
+	}
+
+	arg := ListUserTransfersParams{
+		Owner:    account1.Owner,
+		PageSize: 10,
+	}
+
+	transfers, err := testQueries.ListUserTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 6)
+
+	arg.Direction = sql.NullString{String: "out", Valid: true}
+	arg.MinAmount = sql.NullInt64{Int64: 0, Valid: true}
+	transfers, err = testQueries.ListUserTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+	for _, transfer := range transfers {
+		require.Equal(t, account1.ID, transfer.FromAccountID)
+	}
 }
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Statuses of an account. Only active accounts can send or receive money.
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+var (
+	// ErrInvalidStatusTransition is returned when an account cannot move
+	// from its current status to the requested one.
+	ErrInvalidStatusTransition = errors.New("invalid account status transition")
+	// ErrAccountNotEmpty is returned when closing an account that still
+	// has money in it, or funds held on it.
+	ErrAccountNotEmpty = errors.New("account must have a zero balance and no holds to be closed")
+)
+
+// accountStatusTransitions lists the statuses an account may move to from
+// each status. Frozen accounts must be unfrozen before they can be closed.
+var accountStatusTransitions = map[string][]string{
+	AccountStatusActive: {AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusFrozen: {AccountStatusActive},
+	AccountStatusClosed: {AccountStatusActive},
+}
+
+type UpdateAccountStatusTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// From is the status the account must be in to be moved, so that e.g.
+	// reopening a closed account cannot also lift a freeze. Leave empty to
+	// allow any status the transition table allows.
+	From   string `json:"from"`
+	Status string `json:"status"`
+}
+
+// UpdateAccountStatusTx moves an account to another status. Setting the
+// status it already has is a no-op. An account can only be closed once it
+// is empty, so that no money is stranded in it.
+func (store *SQLStore) UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusTxParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		account = before
+
+		if account.Owner == SystemUsername {
+			return ErrSystemAccount
+		}
+
+		if account.Status == arg.Status {
+			return nil
+		}
+
+		if arg.From != "" && account.Status != arg.From {
+			return fmt.Errorf("%w from %s to %s: account is not %s", ErrInvalidStatusTransition, account.Status, arg.Status, arg.From)
+		}
+
+		if !canTransition(account.Status, arg.Status) {
+			return fmt.Errorf("%w from %s to %s", ErrInvalidStatusTransition, account.Status, arg.Status)
+		}
+
+		if arg.Status == AccountStatusClosed {
+			held, err := q.GetAccountHeldAmount(ctx, account.ID)
+			if err != nil {
+				return err
+			}
+
+			if account.Balance != 0 || held != 0 {
+				return ErrAccountNotEmpty
+			}
+		}
+
+		account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			Status: arg.Status,
+			ID:     account.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account.update_status",
+			ResourceType: AuditResourceAccount,
+			ResourceID:   auditID(account.ID),
+			Before:       before,
+			After:        account,
+		})
+	})
+
+	return account, err
+}
+
+func canTransition(from string, to string) bool {
+	for _, status := range accountStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkActive returns the error for the first of the accounts that cannot
+// send or receive money.
+func checkActive(accounts ...Account) error {
+	for _, account := range accounts {
+		switch account.Status {
+		case AccountStatusActive:
+		case AccountStatusFrozen:
+			return ErrAccountFrozen
+		case AccountStatusClosed:
+			return ErrAccountClosed
+		default:
+			return fmt.Errorf("account %d has unknown status %q", account.ID, account.Status)
+		}
+	}
+	return nil
+}
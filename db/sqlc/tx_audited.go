@@ -0,0 +1,222 @@
+package db
+
+import "context"
+
+// The methods below override the queries of the same name with ones that
+// also record an audit event, in the same transaction as the change.
+
+func (store *SQLStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        account.Owner,
+			Action:       "account.create",
+			ResourceType: AuditResourceAccount,
+			ResourceID:   auditID(account.ID),
+			After:        account,
+		})
+	})
+
+	return account, err
+}
+
+// CreateSession records a login.
+func (store *SQLStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		session, err = q.CreateSession(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        session.Username,
+			Action:       "user.login",
+			ResourceType: AuditResourceSession,
+			ResourceID:   session.ID.String(),
+			After:        newAuditSession(session),
+		})
+	})
+
+	return session, err
+}
+
+func (store *SQLStore) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.UpdateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        user.Username,
+			Action:       "user.update",
+			ResourceType: AuditResourceUser,
+			ResourceID:   user.Username,
+			Before:       newAuditUser(before),
+			After:        newAuditUser(user),
+		})
+	})
+
+	return user, err
+}
+
+// UpsertTOTPSecret records the enrollment of a TOTP secret, but never the
+// secret itself.
+func (store *SQLStore) UpsertTOTPSecret(ctx context.Context, arg UpsertTOTPSecretParams) (TotpSecret, error) {
+	var secret TotpSecret
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		secret, err = q.UpsertTOTPSecret(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        secret.Username,
+			Action:       "user.enroll_totp",
+			ResourceType: AuditResourceUser,
+			ResourceID:   secret.Username,
+			After:        newAuditTOTP(secret),
+		})
+	})
+
+	return secret, err
+}
+
+func (store *SQLStore) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	var schedule ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		schedule, err = q.CreateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        schedule.Owner,
+			Action:       "scheduled_transfer.create",
+			ResourceType: AuditResourceScheduledTransfer,
+			ResourceID:   auditID(schedule.ID),
+			After:        schedule,
+		})
+	})
+
+	return schedule, err
+}
+
+func (store *SQLStore) UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	var schedule ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetScheduledTransfer(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		schedule, err = q.UpdateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        schedule.Owner,
+			Action:       "scheduled_transfer.update",
+			ResourceType: AuditResourceScheduledTransfer,
+			ResourceID:   auditID(schedule.ID),
+			Before:       before,
+			After:        schedule,
+		})
+	})
+
+	return schedule, err
+}
+
+func (store *SQLStore) DeleteScheduledTransfer(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetScheduledTransfer(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteScheduledTransfer(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        before.Owner,
+			Action:       "scheduled_transfer.delete",
+			ResourceType: AuditResourceScheduledTransfer,
+			ResourceID:   auditID(id),
+			Before:       before,
+		})
+	})
+}
+
+func (store *SQLStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	var apiKey ApiKey
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		apiKey, err = q.CreateAPIKey(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        apiKey.Username,
+			Action:       "api_key.create",
+			ResourceType: AuditResourceAPIKey,
+			ResourceID:   auditID(apiKey.ID),
+			After:        newAuditAPIKey(apiKey),
+		})
+	})
+
+	return apiKey, err
+}
+
+func (store *SQLStore) RevokeAPIKey(ctx context.Context, id int64) (ApiKey, error) {
+	var apiKey ApiKey
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAPIKey(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		apiKey, err = q.RevokeAPIKey(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        apiKey.Username,
+			Action:       "api_key.revoke",
+			ResourceType: AuditResourceAPIKey,
+			ResourceID:   auditID(apiKey.ID),
+			Before:       newAuditAPIKey(before),
+			After:        newAuditAPIKey(apiKey),
+		})
+	})
+
+	return apiKey, err
+}
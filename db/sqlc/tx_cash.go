@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// SystemUsername owns the cash accounts that deposits and withdrawals are
+// booked against. It cannot log in.
+const SystemUsername = "system"
+
+// Kinds of operation an entry can be written by.
+const (
+	EntryKindTransfer   = "transfer"
+	EntryKindDeposit    = "deposit"
+	EntryKindWithdrawal = "withdrawal"
+	EntryKindAdjustment = "adjustment"
+	EntryKindReversal   = "reversal"
+)
+
+var (
+	// ErrSystemAccount is returned when a customer operation names one of
+	// the system cash accounts.
+	ErrSystemAccount = errors.New("system accounts cannot be used directly")
+	// ErrCashAccountNotFound is returned when there is no cash account for
+	// the currency of the account being deposited to or withdrawn from.
+	ErrCashAccountNotFound = errors.New("cash account not found")
+)
+
+type CashTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Amount is always positive; WithdrawTx debits it from the account.
+	Amount      int64  `json:"amount"`
+	Reference   string `json:"reference"`
+	Description string `json:"description"`
+}
+
+type CashTxResult struct {
+	Account     Account `json:"account"`
+	Entry       Entry   `json:"entry"`
+	CashAccount Account `json:"cash_account"`
+	CashEntry   Entry   `json:"cash_entry"`
+	// LedgerTransaction is the journal both entries belong to.
+	LedgerTransaction LedgerTransaction `json:"ledger_transaction"`
+}
+
+// DepositTx credits money from outside the bank to an account, debiting the
+// cash account of its currency.
+func (store *SQLStore) DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	return store.cashTx(ctx, EntryKindDeposit, arg.Amount, arg)
+}
+
+// WithdrawTx pays money out of an account, crediting the cash account of
+// its currency. The account may not go below its overdraft limit.
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	return store.cashTx(ctx, EntryKindWithdrawal, -arg.Amount, arg)
+}
+
+// cashTx adds amount to the account and takes it from the cash account,
+// writing an entry of the given kind on each.
+func (store *SQLStore) cashTx(ctx context.Context, kind string, amount int64, arg CashTxParams) (CashTxResult, error) {
+	var result CashTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Owner == SystemUsername {
+			return ErrSystemAccount
+		}
+
+		cashAccount, err := findCashAccount(ctx, q, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(ctx, q, account.ID, cashAccount.ID)
+		if err != nil {
+			return err
+		}
+		account = accounts[account.ID]
+
+		if err := checkActive(account); err != nil {
+			return err
+		}
+
+		if amount < 0 {
+			spendable, err := spendableBalance(ctx, q, account)
+			if err != nil {
+				return err
+			}
+
+			if spendable < -amount {
+				return ErrInsufficientFunds
+			}
+		}
+
+		result.LedgerTransaction, err = q.CreateLedgerTransaction(ctx, CreateLedgerTransactionParams{
+			Kind: kind,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:           account.ID,
+			Amount:              amount,
+			Kind:                kind,
+			Reference:           arg.Reference,
+			Description:         arg.Description,
+			LedgerTransactionID: result.LedgerTransaction.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.CashEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:           cashAccount.ID,
+			Amount:              -amount,
+			Kind:                kind,
+			Reference:           arg.Reference,
+			Description:         arg.Description,
+			LedgerTransactionID: result.LedgerTransaction.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if account.ID < cashAccount.ID {
+			result.Account, result.CashAccount, err = addMoney(ctx, q, account.ID, amount, cashAccount.ID, -amount)
+		} else {
+			result.CashAccount, result.Account, err = addMoney(ctx, q, cashAccount.ID, -amount, account.ID, amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account." + kind,
+			ResourceType: AuditResourceAccount,
+			ResourceID:   auditID(account.ID),
+			Before:       account,
+			After:        result,
+		})
+	})
+
+	return result, err
+}
+
+// findCashAccount returns the system cash account of currency.
+func findCashAccount(ctx context.Context, q *Queries, currency string) (Account, error) {
+	account, err := q.GetCashAccount(ctx, currency)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return account, fmt.Errorf("%w for %s", ErrCashAccountNotFound, currency)
+		}
+		return account, err
+	}
+
+	return account, nil
+}
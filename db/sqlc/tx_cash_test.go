@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountInCurrency(t, utils.USD)
+
+	cashAccount, err := testQueries.GetCashAccount(context.Background(), utils.USD)
+	require.NoError(t, err)
+	require.Equal(t, SystemUsername, cashAccount.Owner)
+
+	n := 5
+	amount := int64(10)
+
+	errs := make(chan error)
+	results := make(chan CashTxResult)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.DepositTx(context.Background(), CashTxParams{
+				AccountID:   account.ID,
+				Amount:      amount,
+				Reference:   utils.RandomString(12),
+				Description: "cash deposit",
+			})
+			errs <- err
+			results <- result
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+
+		result := <-results
+		require.Equal(t, account.ID, result.Account.ID)
+		require.Equal(t, cashAccount.ID, result.CashAccount.ID)
+
+		require.Equal(t, account.ID, result.Entry.AccountID)
+		require.Equal(t, amount, result.Entry.Amount)
+		require.Equal(t, EntryKindDeposit, result.Entry.Kind)
+		require.NotEmpty(t, result.Entry.Reference)
+		require.Equal(t, "cash deposit", result.Entry.Description)
+		require.False(t, result.Entry.TransferID.Valid)
+
+		require.Equal(t, cashAccount.ID, result.CashEntry.AccountID)
+		require.Equal(t, -amount, result.CashEntry.Amount)
+		require.Equal(t, result.Entry.Reference, result.CashEntry.Reference)
+	}
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+int64(n)*amount, updatedAccount.Balance)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithBalance(t, 100)
+
+	result, err := store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    30,
+		Reference: utils.RandomString(12),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.Account.Balance)
+	require.Equal(t, int64(-30), result.Entry.Amount)
+	require.Equal(t, EntryKindWithdrawal, result.Entry.Kind)
+	require.Equal(t, int64(30), result.CashEntry.Amount)
+
+	_, err = store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    71,
+		Reference: utils.RandomString(12),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), updatedAccount.Balance)
+}
+
+func TestCashTxRejected(t *testing.T) {
+	store := NewStore(testDB)
+
+	cashAccount, err := testQueries.GetCashAccount(context.Background(), utils.USD)
+	require.NoError(t, err)
+
+	_, err = store.DepositTx(context.Background(), CashTxParams{
+		AccountID: cashAccount.ID,
+		Amount:    10,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	account := createRandomAccountInCurrency(t, utils.USD)
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status: AccountStatusFrozen,
+		ID:     account.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = store.DepositTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: cashAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+}
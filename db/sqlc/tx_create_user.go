@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type CreateUserTxParams struct {
+	CreateUserParams
+	// VerifyCodeHash is the hash of the code sent to the user to verify
+	// their email, valid until VerifyExpiresAt.
+	VerifyCodeHash  string
+	VerifyExpiresAt time.Time
+}
+
+type CreateUserTxResult struct {
+	User        User        `json:"user"`
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// CreateUserTx creates a user along with the code that verifies their email.
+// The code is for the caller to send once the user is committed.
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
+	var result CreateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
+		if err != nil {
+			return err
+		}
+
+		result.VerifyEmail, err = q.CreateVerifyEmail(ctx, CreateVerifyEmailParams{
+			Username:  result.User.Username,
+			Email:     result.User.Email,
+			CodeHash:  arg.VerifyCodeHash,
+			ExpiresAt: arg.VerifyExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        result.User.Username,
+			Action:       "user.create",
+			ResourceType: AuditResourceUser,
+			ResourceID:   result.User.Username,
+			After:        newAuditUser(result.User),
+		})
+	})
+
+	return result, err
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func randomCreateUserTxParams(t *testing.T) CreateUserTxParams {
+	hashedPassword, err := utils.HashPassword(utils.RandomString(6))
+	require.NoError(t, err)
+
+	return CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       utils.RandomOwner(),
+			HashedPassword: hashedPassword,
+			FullName:       utils.RandomOwner(),
+			Email:          utils.RandomEmail(),
+		},
+		VerifyCodeHash:  utils.HashSecretCode(utils.RandomString(32)),
+		VerifyExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestCreateUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	arg := randomCreateUserTxParams(t)
+
+	result, err := store.CreateUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, result.User.Username)
+	require.False(t, result.User.IsEmailVerified)
+
+	require.Equal(t, arg.Username, result.VerifyEmail.Username)
+	require.Equal(t, arg.Email, result.VerifyEmail.Email)
+	require.Equal(t, arg.VerifyCodeHash, result.VerifyEmail.CodeHash)
+	require.False(t, result.VerifyEmail.UsedAt.Valid)
+}
@@ -0,0 +1,220 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Statuses of a hold. Only active holds that have not expired reserve funds.
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+var (
+	// ErrHoldNotActive is returned when capturing or releasing a hold that
+	// was already captured or released.
+	ErrHoldNotActive = errors.New("hold is no longer active")
+	// ErrHoldExpired is returned when capturing a hold past its expiry.
+	ErrHoldExpired = errors.New("hold has expired")
+	// ErrCaptureExceedsHold is returned when capturing more than was held.
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the hold")
+)
+
+type PlaceHoldParams struct {
+	AccountID   int64     `json:"account_id"`
+	ToAccountID int64     `json:"to_account_id"`
+	Amount      int64     `json:"amount"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type CaptureHoldParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount is transferred out of the hold. Zero captures all of it; any
+	// part that is not captured is released.
+	Amount int64 `json:"amount"`
+}
+
+type CaptureHoldResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// PlaceHold reserves funds on an account for a later transfer to another
+// account of the same currency. The funds no longer count towards the
+// account's available balance until the hold is captured, released or
+// expires.
+func (store *SQLStore) PlaceHold(ctx context.Context, arg PlaceHoldParams) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(ctx, q, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		account := accounts[arg.AccountID]
+
+		if account.Owner == SystemUsername || toAccount.Owner == SystemUsername {
+			return ErrSystemAccount
+		}
+
+		// A capture has no exchange rate to run at.
+		if account.Currency != toAccount.Currency {
+			return ErrExchangeRateRequired
+		}
+
+		if err := checkActive(account, toAccount); err != nil {
+			return err
+		}
+
+		spendable, err := spendableBalance(ctx, q, account)
+		if err != nil {
+			return err
+		}
+
+		if spendable < arg.Amount {
+			return ErrInsufficientFunds
+		}
+
+		hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   arg.AccountID,
+			ToAccountID: arg.ToAccountID,
+			Amount:      arg.Amount,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "hold.place",
+			ResourceType: AuditResourceHold,
+			ResourceID:   auditID(hold.ID),
+			After:        hold,
+		})
+	})
+
+	return hold, err
+}
+
+// CaptureHold settles an active hold with a transfer to the account it was
+// placed for.
+func (store *SQLStore) CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error) {
+	var result CaptureHoldResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := activeHold(ctx, q, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if !hold.ExpiresAt.After(time.Now()) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		// Settling the hold first hands its funds back to the available
+		// balance, for the transfer below to spend.
+		_, err = q.SettleHold(ctx, SettleHoldParams{
+			Status: HoldStatusCaptured,
+			ID:     hold.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.SettleHold(ctx, SettleHoldParams{
+			Status:     HoldStatusCaptured,
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+			ID:         hold.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "hold.capture",
+			ResourceType: AuditResourceHold,
+			ResourceID:   auditID(hold.ID),
+			Before:       hold,
+			After:        result,
+		})
+	})
+
+	return result, err
+}
+
+// ReleaseHold cancels an active hold, making its funds available again.
+func (store *SQLStore) ReleaseHold(ctx context.Context, holdID int64) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		active, err := activeHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+
+		status := HoldStatusReleased
+		if !active.ExpiresAt.After(time.Now()) {
+			status = HoldStatusExpired
+		}
+
+		hold, err = q.SettleHold(ctx, SettleHoldParams{
+			Status: status,
+			ID:     active.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "hold.release",
+			ResourceType: AuditResourceHold,
+			ResourceID:   auditID(hold.ID),
+			Before:       active,
+			After:        hold,
+		})
+	})
+
+	return hold, err
+}
+
+// activeHold locks a hold that has not been captured or released yet.
+func activeHold(ctx context.Context, q *Queries, holdID int64) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.Status != HoldStatusActive {
+		return hold, ErrHoldNotActive
+	}
+
+	return hold, nil
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestPlaceHold(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	hold, err := store.PlaceHold(context.Background(), PlaceHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      600,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusActive, hold.Status)
+	require.Equal(t, int64(600), hold.Amount)
+
+	held, err := testQueries.GetAccountHeldAmount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(600), held)
+
+	// The held funds can be neither held again nor transferred.
+	_, err = store.PlaceHold(context.Background(), PlaceHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      600,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        500,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        400,
+	})
+	require.NoError(t, err)
+
+	// Holds are only placed between accounts of the same currency.
+	account3 := createRandomAccountInCurrency(t, utils.EUR)
+	_, err = store.PlaceHold(context.Background(), PlaceHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account3.ID,
+		Amount:      1,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrExchangeRateRequired)
+}
+
+func TestCaptureHold(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	hold, err := store.PlaceHold(context.Background(), PlaceHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      1000,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CaptureHold(context.Background(), CaptureHoldParams{
+		HoldID: hold.ID,
+		Amount: 1001,
+	})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	// Capturing part of the hold releases the rest.
+	result, err := store.CaptureHold(context.Background(), CaptureHoldParams{
+		HoldID: hold.ID,
+		Amount: 700,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusCaptured, result.Hold.Status)
+	require.True(t, result.Hold.TransferID.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Hold.TransferID.Int64)
+	require.True(t, result.Hold.SettledAt.Valid)
+	require.Equal(t, int64(700), result.Transfer.Transfer.Amount)
+	require.Equal(t, int64(300), result.Transfer.FromAccount.Balance)
+	require.Equal(t, int64(700), result.Transfer.ToAccount.Balance)
+
+	held, err := testQueries.GetAccountHeldAmount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = store.CaptureHold(context.Background(), CaptureHoldParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestReleaseHold(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	hold, err := store.PlaceHold(context.Background(), PlaceHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      1000,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	released, err := store.ReleaseHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusReleased, released.Status)
+	require.False(t, released.TransferID.Valid)
+
+	held, err := testQueries.GetAccountHeldAmount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = store.ReleaseHold(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestHoldExpiry(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	hold, err := store.PlaceHold(context.Background(), PlaceHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      1000,
+		ExpiresAt:   time.Now().Add(time.Second),
+	})
+	require.NoError(t, err)
+
+	time.Sleep(1100 * time.Millisecond)
+
+	// An expired hold stops reserving funds on its own.
+	held, err := testQueries.GetAccountHeldAmount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = store.CaptureHold(context.Background(), CaptureHoldParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	released, err := store.ReleaseHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, released.Status)
+}
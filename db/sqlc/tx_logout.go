@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSessionNotFound is returned by LogoutTx when the refresh token session
+// does not exist or belongs to another user.
+var ErrSessionNotFound = errors.New("session not found")
+
+type LogoutTxParams struct {
+	Username             string    `json:"username"`
+	AccessTokenID        uuid.UUID `json:"access_token_id"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	// SessionID is the refresh token session to block alongside the
+	// access token. Its refresh token, which has the same id, is revoked
+	// until SessionExpiresAt.
+	SessionID        uuid.UUID `json:"session_id"`
+	SessionExpiresAt time.Time `json:"session_expires_at"`
+	// AllDevices blocks every session of the user, revokes their API keys
+	// and rejects all tokens issued to them before RevokedAt.
+	AllDevices bool      `json:"all_devices"`
+	RevokedAt  time.Time `json:"revoked_at"`
+}
+
+// LogoutTx revokes the caller's access token and blocks their refresh token
+// sessions, revoking the refresh token too, in a single transaction.
+func (store *SQLStore) LogoutTx(ctx context.Context, arg LogoutTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.CreateRevokedToken(ctx, CreateRevokedTokenParams{
+			ID:        arg.AccessTokenID,
+			Username:  arg.Username,
+			ExpiresAt: arg.AccessTokenExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.AllDevices {
+			err = q.BlockUserSessions(ctx, arg.Username)
+			if err != nil {
+				return err
+			}
+
+			err = q.RevokeUserAPIKeys(ctx, arg.Username)
+			if err != nil {
+				return err
+			}
+
+			err = q.RevokeUserTokens(ctx, RevokeUserTokensParams{
+				RevokedAt: arg.RevokedAt,
+				Username:  arg.Username,
+			})
+			if err != nil {
+				return err
+			}
+
+			return recordAudit(ctx, q, auditEvent{
+				Actor:        arg.Username,
+				Action:       "user.logout_all",
+				ResourceType: AuditResourceUser,
+				ResourceID:   arg.Username,
+				After:        arg,
+			})
+		}
+
+		rows, err := q.BlockSession(ctx, BlockSessionParams{
+			ID:       arg.SessionID,
+			Username: arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrSessionNotFound
+		}
+
+		err = q.CreateRevokedToken(ctx, CreateRevokedTokenParams{
+			ID:        arg.SessionID,
+			Username:  arg.Username,
+			ExpiresAt: arg.SessionExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        arg.Username,
+			Action:       "user.logout",
+			ResourceType: AuditResourceSession,
+			ResourceID:   arg.SessionID.String(),
+			After:        arg,
+		})
+	})
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestLogoutTx(t *testing.T) {
+	store := NewStore(testDB)
+	session := createRandomSession(t)
+	accessTokenID := uuid.New()
+
+	err := store.LogoutTx(context.Background(), LogoutTxParams{
+		Username:             session.Username,
+		AccessTokenID:        accessTokenID,
+		AccessTokenExpiresAt: time.Now().Add(time.Minute),
+		SessionID:            session.ID,
+		SessionExpiresAt:     session.ExpiresAt,
+	})
+	require.NoError(t, err)
+
+	blocked, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       accessTokenID,
+		Username: session.Username,
+		IssuedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// So is the refresh token of the session.
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       session.ID,
+		Username: session.Username,
+		IssuedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// The session of another user cannot be blocked, and nothing is revoked.
+	other := createRandomSession(t)
+	err = store.LogoutTx(context.Background(), LogoutTxParams{
+		Username:             session.Username,
+		AccessTokenID:        uuid.New(),
+		AccessTokenExpiresAt: time.Now().Add(time.Minute),
+		SessionID:            other.ID,
+	})
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	other, err = testQueries.GetSession(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.False(t, other.IsBlocked)
+}
+
+func TestLogoutTxAllDevices(t *testing.T) {
+	store := NewStore(testDB)
+	session1 := createRandomSession(t)
+
+	session2, err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     session1.Username,
+		RefreshToken: utils.RandomString(32),
+		UserAgent:    utils.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	owner, err := testQueries.GetUser(context.Background(), session1.Username)
+	require.NoError(t, err)
+	apiKey := createRandomAPIKey(t, owner)
+
+	revokedAt := time.Now()
+	err = store.LogoutTx(context.Background(), LogoutTxParams{
+		Username:             session1.Username,
+		AccessTokenID:        uuid.New(),
+		AccessTokenExpiresAt: revokedAt.Add(time.Minute),
+		AllDevices:           true,
+		RevokedAt:            revokedAt,
+	})
+	require.NoError(t, err)
+
+	for _, id := range []uuid.UUID{session1.ID, session2.ID} {
+		session, err := testQueries.GetSession(context.Background(), id)
+		require.NoError(t, err)
+		require.True(t, session.IsBlocked)
+	}
+
+	apiKey, err = testQueries.GetAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.True(t, apiKey.RevokedAt.Valid)
+
+	user, err := testQueries.GetUser(context.Background(), session1.Username)
+	require.NoError(t, err)
+	require.WithinDuration(t, revokedAt, user.TokensRevokedAt, time.Second)
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: session1.Username,
+		IssuedAt: revokedAt.Add(-time.Second),
+	})
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type ChangePasswordTxParams struct {
+	Username       string
+	HashedPassword string
+	// ChangedAt is stored as the user's password_changed_at. Tokens issued
+	// before it are rejected from then on.
+	ChangedAt time.Time
+}
+
+// ChangePasswordTx stores a new password, blocks every refresh token session
+// of the user and revokes their API keys, so that whoever held the old
+// password cannot keep renewing access tokens or use a key they created.
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = changePassword(ctx, q, "user.change_password", arg)
+		return err
+	})
+
+	return user, err
+}
+
+type ResetPasswordTxParams struct {
+	CodeHash       string
+	HashedPassword string
+	ChangedAt      time.Time
+}
+
+// ResetPasswordTx consumes a password reset code and changes the password
+// of the user it was sent to, like ChangePasswordTx.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		reset, err := q.UsePasswordReset(ctx, arg.CodeHash)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidCode
+			}
+			return err
+		}
+
+		user, err = changePassword(ctx, q, "user.reset_password", ChangePasswordTxParams{
+			Username:       reset.Username,
+			HashedPassword: arg.HashedPassword,
+			ChangedAt:      arg.ChangedAt,
+		})
+		return err
+	})
+
+	return user, err
+}
+
+// changePassword stores the new password, blocks the user's sessions,
+// revokes their API keys and records the change as action.
+func changePassword(ctx context.Context, q *Queries, action string, arg ChangePasswordTxParams) (User, error) {
+	before, err := q.GetUser(ctx, arg.Username)
+	if err != nil {
+		return before, err
+	}
+
+	user, err := q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+		HashedPassword:    arg.HashedPassword,
+		PasswordChangedAt: arg.ChangedAt,
+		Username:          arg.Username,
+	})
+	if err != nil {
+		return user, err
+	}
+
+	err = q.BlockUserSessions(ctx, arg.Username)
+	if err != nil {
+		return user, err
+	}
+
+	err = q.RevokeUserAPIKeys(ctx, arg.Username)
+	if err != nil {
+		return user, err
+	}
+
+	return user, recordAudit(ctx, q, auditEvent{
+		Actor:        arg.Username,
+		Action:       action,
+		ResourceType: AuditResourceUser,
+		ResourceID:   arg.Username,
+		Before:       newAuditUser(before),
+		After:        newAuditUser(user),
+	})
+}
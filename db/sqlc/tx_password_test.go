@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	session := createRandomSession(t)
+
+	owner, err := testQueries.GetUser(context.Background(), session.Username)
+	require.NoError(t, err)
+	apiKey := createRandomAPIKey(t, owner)
+
+	hashedPassword, err := utils.HashPassword(utils.RandomString(8))
+	require.NoError(t, err)
+
+	changedAt := time.Now()
+	user, err := store.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		Username:       session.Username,
+		HashedPassword: hashedPassword,
+		ChangedAt:      changedAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, user.HashedPassword)
+	require.WithinDuration(t, changedAt, user.PasswordChangedAt, time.Second)
+
+	blocked, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	// API keys created with the old password are revoked too.
+	apiKey, err = testQueries.GetAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	require.True(t, apiKey.RevokedAt.Valid)
+
+	// Tokens issued before the change are rejected, later ones are not.
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: session.Username,
+		IssuedAt: changedAt.Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: session.Username,
+		IssuedAt: changedAt.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	session := createRandomSession(t)
+
+	reset, err := testQueries.CreatePasswordReset(context.Background(), CreatePasswordResetParams{
+		Username:  session.Username,
+		CodeHash:  utils.HashSecretCode(utils.RandomString(32)),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	hashedPassword, err := utils.HashPassword(utils.RandomString(8))
+	require.NoError(t, err)
+
+	arg := ResetPasswordTxParams{
+		CodeHash:       reset.CodeHash,
+		HashedPassword: hashedPassword,
+		ChangedAt:      time.Now(),
+	}
+
+	user, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, session.Username, user.Username)
+	require.Equal(t, hashedPassword, user.HashedPassword)
+
+	blocked, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	// Codes are single-use.
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidCode)
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+)
+
+type ReconcileAccountTxParams struct {
+	AccountID   int64  `json:"account_id"`
+	Description string `json:"description"`
+}
+
+type ReconcileAccountTxResult struct {
+	Account Account `json:"account"`
+	// Drift is how far the balance was above the sum of the entries of the
+	// account. The remaining fields are only set when it was not zero.
+	Drift             int64             `json:"drift"`
+	Entry             Entry             `json:"entry"`
+	CashEntry         Entry             `json:"cash_entry"`
+	LedgerTransaction LedgerTransaction `json:"ledger_transaction"`
+}
+
+// ReconcileAccountTx writes an adjustment entry that brings the entries of
+// an account back in line with its balance. The counterpart is booked on the
+// cash account of its currency, whose balance moves with it, so the journal
+// balances and the cash account does not drift in turn.
+func (store *SQLStore) ReconcileAccountTx(ctx context.Context, arg ReconcileAccountTxParams) (ReconcileAccountTxResult, error) {
+	var result ReconcileAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// A cash account cannot be the counterpart of its own adjustment.
+		if account.Owner == SystemUsername {
+			return ErrSystemAccount
+		}
+
+		cashAccount, err := findCashAccount(ctx, q, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(ctx, q, account.ID, cashAccount.ID)
+		if err != nil {
+			return err
+		}
+		before := accounts[account.ID]
+		result.Account = before
+
+		total, err := q.GetAccountEntriesTotal(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Drift = result.Account.Balance - total
+		if result.Drift == 0 {
+			return nil
+		}
+
+		result.LedgerTransaction, err = q.CreateLedgerTransaction(ctx, CreateLedgerTransactionParams{
+			Kind: EntryKindAdjustment,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:           account.ID,
+			Amount:              result.Drift,
+			Kind:                EntryKindAdjustment,
+			Description:         arg.Description,
+			LedgerTransactionID: result.LedgerTransaction.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.CashEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:           cashAccount.ID,
+			Amount:              -result.Drift,
+			Kind:                EntryKindAdjustment,
+			Description:         arg.Description,
+			LedgerTransactionID: result.LedgerTransaction.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:    cashAccount.ID,
+			Amout: -result.Drift,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account.reconcile",
+			ResourceType: AuditResourceAccount,
+			ResourceID:   auditID(account.ID),
+			Before:       before,
+			After:        result,
+		})
+	})
+
+	return result, err
+}
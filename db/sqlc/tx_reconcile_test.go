@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestReconcileAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// Setting the balance directly leaves it without matching entries.
+	account := createRandomAccountWithBalance(t, 100)
+
+	cashAccount, err := testQueries.GetCashAccount(context.Background(), utils.USD)
+	require.NoError(t, err)
+
+	result, err := store.ReconcileAccountTx(context.Background(), ReconcileAccountTxParams{
+		AccountID:   account.ID,
+		Description: "reconciliation",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), result.Drift)
+	require.Equal(t, account.Balance, result.Account.Balance)
+
+	require.Equal(t, EntryKindAdjustment, result.LedgerTransaction.Kind)
+	require.Equal(t, int64(100), result.Entry.Amount)
+	require.Equal(t, "reconciliation", result.Entry.Description)
+	require.Equal(t, cashAccount.ID, result.CashEntry.AccountID)
+	require.Equal(t, int64(-100), result.CashEntry.Amount)
+
+	total, err := testQueries.GetAccountEntriesTotal(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, total)
+
+	// The cash account moves with its entry, so it does not drift in turn.
+	cashAfter, err := testQueries.GetAccount(context.Background(), cashAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, cashAccount.Balance-100, cashAfter.Balance)
+
+	events := listResourceAuditEvents(t, AuditResourceAccount, auditID(account.ID))
+	require.NotEmpty(t, events)
+	require.Equal(t, "account.reconcile", events[0].Action)
+
+	// The adjustment is written only once.
+	result, err = store.ReconcileAccountTx(context.Background(), ReconcileAccountTxParams{
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Drift)
+	require.Zero(t, result.LedgerTransaction.ID)
+
+	_, err = store.ReconcileAccountTx(context.Background(), ReconcileAccountTxParams{
+		AccountID: cashAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+}
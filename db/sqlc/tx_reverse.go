@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrTransferIsReversal is returned when asked to reverse a reversal.
+	ErrTransferIsReversal = errors.New("reversals cannot be reversed")
+	// ErrTransferAlreadyReversed is returned when the whole amount of a
+	// transfer has already been refunded.
+	ErrTransferAlreadyReversed = errors.New("transfer is already fully reversed")
+	// ErrReversalExceedsTransfer is returned when a refund is larger than
+	// what is left to reverse of the transfer.
+	ErrReversalExceedsTransfer = errors.New("reversal amount exceeds the amount left to reverse")
+	// ErrReversalTooSmall is returned when a partial refund of a
+	// cross-currency transfer is worth nothing in the source currency.
+	ErrReversalTooSmall = errors.New("reversal amount is too small to refund")
+)
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is taken back from the recipient, in the currency they were
+	// credited in. Zero reverses whatever is left of the transfer.
+	Amount int64 `json:"amount"`
+	// Force lets the reversal take the recipient below its overdraft limit
+	// and go through when either account is frozen. Closed accounts are
+	// never reversed into or out of.
+	Force bool `json:"force"`
+}
+
+type ReverseTransferTxResult struct {
+	// Reversal is the compensating transfer, from the recipient of the
+	// original back to its sender.
+	Reversal TransferTxResult `json:"reversal"`
+	// Original is the reversed transfer, with its updated reversed amount.
+	Original Transfer `json:"original"`
+}
+
+// ReverseTransferTx refunds all or part of a transfer with a compensating
+// transfer in the opposite direction. A cross-currency transfer is refunded
+// at its original rate, and reversing all of it returns exactly the amount
+// that was debited.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Locking the original serialises concurrent reversals of it, so
+		// they cannot refund more than it moved between them.
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOf.Valid {
+			return ErrTransferIsReversal
+		}
+
+		left := original.ToAmount - original.ReversedAmount
+		if left <= 0 {
+			return ErrTransferAlreadyReversed
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = left
+		}
+
+		if amount > left {
+			return ErrReversalExceedsTransfer
+		}
+
+		// Refunds are converted cumulatively so that rounding never adds up
+		// to more, or less, than the original debit.
+		refund := refundedAmount(original, original.ReversedAmount+amount) - refundedAmount(original, original.ReversedAmount)
+		if refund <= 0 {
+			return ErrReversalTooSmall
+		}
+
+		accounts, err := lockTransferAccounts(ctx, q, original.ToAccountID, original.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		// Money never moves in or out of a closed account, even by force.
+		if accounts.from.Status == AccountStatusClosed || accounts.to.Status == AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		if !arg.Force {
+			if err := checkActive(accounts.from, accounts.to); err != nil {
+				return err
+			}
+
+			spendable, err := spendableBalance(ctx, q, accounts.from)
+			if err != nil {
+				return err
+			}
+
+			if spendable < amount {
+				return ErrInsufficientFunds
+			}
+		}
+
+		exchangeRate, err := inverseRate(original.ExchangeRate)
+		if err != nil {
+			return err
+		}
+
+		result.Reversal, err = bookTransfer(ctx, q, EntryKindReversal, accounts, CreateTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        amount,
+			FromCurrency:  original.ToCurrency,
+			ToAmount:      refund,
+			ToCurrency:    original.FromCurrency,
+			ExchangeRate:  exchangeRate,
+			RateUpdatedAt: original.RateUpdatedAt,
+			ReversalOf:    sql.NullInt64{Int64: original.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Original, err = q.AddTransferReversedAmount(ctx, AddTransferReversedAmountParams{
+			Amount: amount,
+			ID:     original.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "transfer.reverse",
+			ResourceType: AuditResourceTransfer,
+			ResourceID:   auditID(original.ID),
+			Before:       original,
+			After:        result,
+		})
+	})
+
+	return result, err
+}
+
+// refundedAmount is how much of the debited amount of a transfer is paid
+// back once reversed of its credited amount has been refunded.
+func refundedAmount(transfer Transfer, reversed int64) int64 {
+	refunded := new(big.Int).Mul(big.NewInt(transfer.Amount), big.NewInt(reversed))
+	return refunded.Quo(refunded, big.NewInt(transfer.ToAmount)).Int64()
+}
+
+// inverseRate turns the rate of a transfer into the rate of its reversal.
+func inverseRate(rate string) (string, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return "", fmt.Errorf("invalid exchange rate %q", rate)
+	}
+	return r.Inv(r).FloatString(10), nil
+}
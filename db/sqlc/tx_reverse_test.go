@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+
+	reversal := result.Reversal
+	require.Equal(t, account2.ID, reversal.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, reversal.Transfer.ToAccountID)
+	require.Equal(t, int64(30), reversal.Transfer.Amount)
+	require.Equal(t, int64(30), reversal.Transfer.ToAmount)
+	require.True(t, reversal.Transfer.ReversalOf.Valid)
+	require.Equal(t, transfer.Transfer.ID, reversal.Transfer.ReversalOf.Int64)
+	require.Equal(t, EntryKindReversal, reversal.LedgerTransaction.Kind)
+	require.Equal(t, EntryKindReversal, reversal.FromEntry.Kind)
+	require.Equal(t, int64(70), reversal.FromAccount.Balance)
+	require.Equal(t, int64(930), reversal.ToAccount.Balance)
+	require.Equal(t, int64(30), result.Original.ReversedAmount)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     71,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: reversal.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferIsReversal)
+
+	// Without an amount the rest of the transfer is reversed.
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.Reversal.Transfer.Amount)
+	require.Equal(t, int64(100), result.Original.ReversedAmount)
+	require.Zero(t, result.Reversal.FromAccount.Balance)
+	require.Equal(t, account1.Balance, result.Reversal.ToAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+}
+
+func TestReverseTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+				TransferID: transfer.Transfer.ID,
+				Amount:     40,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+	}
+	require.Equal(t, 2, succeeded)
+
+	updated, err := testQueries.GetTransfer(context.Background(), transfer.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(80), updated.ReversedAmount)
+}
+
+func TestReverseTransferTxCrossCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountInCurrency(t, utils.EUR)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		ToAmount:      92,
+		ExchangeRate:  "0.92",
+		RateUpdatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	// Partial refunds are rounded down, and the last one makes up for it.
+	var refunded int64
+	for _, amount := range []int64{45, 45, 2} {
+		result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+			TransferID: transfer.Transfer.ID,
+			Amount:     amount,
+		})
+		require.NoError(t, err)
+
+		reversal := result.Reversal
+		require.Equal(t, utils.EUR, reversal.Transfer.FromCurrency)
+		require.Equal(t, utils.USD, reversal.Transfer.ToCurrency)
+		require.Equal(t, amount, reversal.Transfer.Amount)
+		require.Len(t, reversal.ExchangeEntries, 2)
+		require.Equal(t, amount, reversal.ExchangeEntries[0].Amount)
+		require.Equal(t, -reversal.Transfer.ToAmount, reversal.ExchangeEntries[1].Amount)
+
+		refunded += reversal.Transfer.ToAmount
+	}
+	require.Equal(t, int64(100), refunded)
+
+	updated, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updated.Balance)
+}
+
+func TestReverseTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithBalance(t, 1000)
+	account2 := createRandomAccountWithBalance(t, 0)
+	account3 := createRandomAccountWithBalance(t, 0)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	// The recipient has spent the money already.
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account3.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Force:      true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-100), result.Reversal.FromAccount.Balance)
+	require.Equal(t, account1.Balance, result.Reversal.ToAccount.Balance)
+}
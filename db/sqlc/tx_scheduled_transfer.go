@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// scheduledTransferActive is the status of a schedule that is executed.
+const scheduledTransferActive = "active"
+
+// ErrScheduledRunDone is returned by RunScheduledTransferTx when the run was
+// already made, or the schedule stopped being active since it was claimed.
+var ErrScheduledRunDone = errors.New("scheduled transfer run is no longer due")
+
+type RunScheduledTransferTxParams struct {
+	ID int64 `json:"id"`
+	// Runs is how many runs the schedule had made when it was claimed. The
+	// run is only made if it still has.
+	Runs int32 `json:"runs"`
+	// NextRunAt and Status are what the schedule is left with after the run.
+	NextRunAt time.Time `json:"next_run_at"`
+	Status    string    `json:"status"`
+}
+
+type RunScheduledTransferTxResult struct {
+	Schedule ScheduledTransfer `json:"schedule"`
+	Transfer TransferTxResult  `json:"transfer"`
+}
+
+// RunScheduledTransferTx makes the current run of a schedule and advances it
+// to the next one in the same transaction, so a run is never paid twice: a
+// retry of a committed run finds the schedule already advanced. The run pays
+// the amount of the schedule at the time it is made.
+func (store *SQLStore) RunScheduledTransferTx(ctx context.Context, arg RunScheduledTransferTxParams) (RunScheduledTransferTxResult, error) {
+	var result RunScheduledTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		schedule, err := q.GetScheduledTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrScheduledRunDone
+			}
+			return err
+		}
+
+		if schedule.Runs != arg.Runs || schedule.Status != scheduledTransferActive {
+			return ErrScheduledRunDone
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: schedule.FromAccountID,
+			ToAccountID:   schedule.ToAccountID,
+			Amount:        schedule.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.RecordScheduledTransferSuccess(ctx, RecordScheduledTransferSuccessParams{
+			LastTransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+			NextRunAt:      arg.NextRunAt,
+			Status:         arg.Status,
+			ID:             schedule.ID,
+			Runs:           schedule.Runs,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Schedule, err = q.GetScheduledTransfer(ctx, schedule.ID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "transfer.create",
+			ResourceType: AuditResourceTransfer,
+			ResourceID:   auditID(result.Transfer.Transfer.ID),
+			After:        result.Transfer,
+		})
+	})
+
+	return result, err
+}
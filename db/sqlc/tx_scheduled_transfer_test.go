@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	schedule := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      schedule.FromAccountID,
+		Balance: schedule.Amount,
+	})
+	require.NoError(t, err)
+
+	arg := RunScheduledTransferTxParams{
+		ID:        schedule.ID,
+		Runs:      schedule.Runs,
+		NextRunAt: schedule.NextRunAt.AddDate(0, 0, 1),
+		Status:    "active",
+	}
+
+	result, err := store.RunScheduledTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, schedule.Amount, result.Transfer.Transfer.Amount)
+	require.Equal(t, int64(0), result.Transfer.FromAccount.Balance)
+
+	// The schedule advanced with the transfer.
+	require.Equal(t, schedule.Runs+1, result.Schedule.Runs)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Schedule.LastTransferID.Int64)
+	require.WithinDuration(t, arg.NextRunAt, result.Schedule.NextRunAt, time.Second)
+	require.False(t, result.Schedule.LockedUntil.Valid)
+
+	// A retry of the run, even for another amount, pays nothing.
+	_, err = store.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     schedule.ID,
+		Amount: sql.NullInt64{Int64: schedule.Amount + 1, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.RunScheduledTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrScheduledRunDone)
+
+	account, err := testQueries.GetAccount(context.Background(), schedule.FromAccountID)
+	require.NoError(t, err)
+	require.Zero(t, account.Balance)
+}
+
+func TestRunScheduledTransferTxPaused(t *testing.T) {
+	store := NewStore(testDB)
+	schedule := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+
+	_, err := store.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     schedule.ID,
+		Status: sql.NullString{String: "paused", Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{
+		ID:        schedule.ID,
+		Runs:      schedule.Runs,
+		NextRunAt: schedule.NextRunAt.AddDate(0, 0, 1),
+		Status:    "active",
+	})
+	require.ErrorIs(t, err, ErrScheduledRunDone)
+
+	paused, err := testQueries.GetScheduledTransfer(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, schedule.Runs, paused.Runs)
+	require.False(t, paused.LastTransferID.Valid)
+}
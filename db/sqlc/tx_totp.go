@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type ConfirmTOTPTxParams struct {
+	Username string
+	// Step is the time step of the code that confirmed the secret, so that
+	// the same code cannot also be used to log in.
+	Step int64
+	// RecoveryCodeHashes replace any recovery codes the user had before.
+	RecoveryCodeHashes []string
+}
+
+// ConfirmTOTPTx enables two-factor authentication with the secret the user
+// enrolled, together with a fresh set of recovery codes.
+func (store *SQLStore) ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (TotpSecret, error) {
+	var secret TotpSecret
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		secret, err = q.ConfirmTOTPSecret(ctx, ConfirmTOTPSecretParams{
+			Step:     arg.Step,
+			Username: arg.Username,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidCode
+			}
+			return err
+		}
+
+		if err := q.DeleteRecoveryCodes(ctx, arg.Username); err != nil {
+			return err
+		}
+
+		for _, codeHash := range arg.RecoveryCodeHashes {
+			_, err := q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username: arg.Username,
+				CodeHash: codeHash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        arg.Username,
+			Action:       "user.enable_totp",
+			ResourceType: AuditResourceUser,
+			ResourceID:   arg.Username,
+			After:        newAuditTOTP(secret),
+		})
+	})
+
+	return secret, err
+}
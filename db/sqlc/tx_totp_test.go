@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestConfirmTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomTOTPSecret(t, user)
+
+	_, err := testQueries.CreateRecoveryCode(context.Background(), CreateRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: utils.RandomString(60),
+	})
+	require.NoError(t, err)
+
+	hashes := []string{utils.RandomString(60), utils.RandomString(60)}
+	secret, err := store.ConfirmTOTPTx(context.Background(), ConfirmTOTPTxParams{
+		Username:           user.Username,
+		Step:               100,
+		RecoveryCodeHashes: hashes,
+	})
+	require.NoError(t, err)
+	require.True(t, secret.ConfirmedAt.Valid)
+	require.Equal(t, int64(100), secret.LastUsedStep)
+
+	// The new recovery codes replace the old ones.
+	codes, err := testQueries.ListUnusedRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, codes, len(hashes))
+	for i, code := range codes {
+		require.Equal(t, hashes[i], code.CodeHash)
+	}
+
+	// A secret is confirmed only once.
+	_, err = store.ConfirmTOTPTx(context.Background(), ConfirmTOTPTxParams{
+		Username: user.Username,
+		Step:     101,
+	})
+	require.ErrorIs(t, err, ErrInvalidCode)
+}
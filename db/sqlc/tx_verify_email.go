@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrInvalidCode is returned when an email verification, password reset or
+// TOTP code does not exist, has expired or was already used.
+var ErrInvalidCode = errors.New("code is invalid, expired or already used")
+
+// VerifyEmailTx consumes an email verification code and marks the email it
+// was sent to as verified. A code sent to an address the user has since
+// replaced verifies nothing.
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, codeHash string) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		verifyEmail, err := q.UseVerifyEmail(ctx, codeHash)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidCode
+			}
+			return err
+		}
+
+		before, err := q.GetUser(ctx, verifyEmail.Username)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.MarkUserEmailVerified(ctx, MarkUserEmailVerifiedParams{
+			Username: verifyEmail.Username,
+			Email:    verifyEmail.Email,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidCode
+			}
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Actor:        user.Username,
+			Action:       "user.verify_email",
+			ResourceType: AuditResourceUser,
+			ResourceID:   user.Username,
+			Before:       newAuditUser(before),
+			After:        newAuditUser(user),
+		})
+	})
+
+	return user, err
+}
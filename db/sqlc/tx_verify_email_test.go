@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func createRandomVerifyEmail(t *testing.T, user User, expiresAt time.Time) VerifyEmail {
+	arg := CreateVerifyEmailParams{
+		Username:  user.Username,
+		Email:     user.Email,
+		CodeHash:  utils.HashSecretCode(utils.RandomString(32)),
+		ExpiresAt: expiresAt,
+	}
+
+	verifyEmail, err := testQueries.CreateVerifyEmail(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, verifyEmail.Username)
+	require.Equal(t, arg.Email, verifyEmail.Email)
+	require.Equal(t, arg.CodeHash, verifyEmail.CodeHash)
+	require.False(t, verifyEmail.UsedAt.Valid)
+
+	return verifyEmail
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	verifyEmail := createRandomVerifyEmail(t, user, time.Now().Add(time.Hour))
+
+	verified, err := store.VerifyEmailTx(context.Background(), verifyEmail.CodeHash)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, verified.Username)
+	require.True(t, verified.IsEmailVerified)
+
+	// Codes are single-use.
+	_, err = store.VerifyEmailTx(context.Background(), verifyEmail.CodeHash)
+	require.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestVerifyEmailTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	verifyEmail := createRandomVerifyEmail(t, user, time.Now().Add(-time.Minute))
+
+	_, err := store.VerifyEmailTx(context.Background(), verifyEmail.CodeHash)
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	_, err = store.VerifyEmailTx(context.Background(), utils.HashSecretCode(utils.RandomString(32)))
+	require.ErrorIs(t, err, ErrInvalidCode)
+}
+
+func TestVerifyEmailTxChangedEmail(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	verifyEmail := createRandomVerifyEmail(t, user, time.Now().Add(time.Hour))
+
+	_, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    sql.NullString{String: utils.RandomEmail(), Valid: true},
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+
+	// The code was sent to the old address, which it can no longer verify.
+	_, err = store.VerifyEmailTx(context.Background(), verifyEmail.CodeHash)
+	require.ErrorIs(t, err, ErrInvalidCode)
+
+	user, err = testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.False(t, user.IsEmailVerified)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -30,6 +31,8 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.Email, user.Email)
 	require.NotZero(t, user.CreatedAt)
 	require.True(t, user.PasswordChangedAt.IsZero())
+	require.Equal(t, utils.DepositorRole, user.Role)
+	require.False(t, user.IsEmailVerified)
 
 	return user
 }
@@ -50,4 +53,71 @@ func TestGetUser(t *testing.T) {
 	require.Equal(t, user1.Email, user2.Email)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
+	require.Equal(t, user1.Role, user2.Role)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	user2, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Role:     utils.BankerRole,
+		Username: user1.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, utils.BankerRole, user2.Role)
+
+	_, err = testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Role:     "superuser",
+		Username: user1.Username,
+	})
+	require.Error(t, err)
+}
+
+func TestUpdateUser(t *testing.T) {
+	user1 := createRandomUser(t)
+	newEmail := utils.RandomEmail()
+
+	user1, err := testQueries.MarkUserEmailVerified(context.Background(), MarkUserEmailVerifiedParams{
+		Username: user1.Username,
+		Email:    user1.Email,
+	})
+	require.NoError(t, err)
+	require.True(t, user1.IsEmailVerified)
+
+	// Changing only the name keeps the email verified.
+	user2, err := testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		FullName: sql.NullString{String: utils.RandomOwner(), Valid: true},
+		Username: user1.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.Email, user2.Email)
+	require.True(t, user2.IsEmailVerified)
+
+	user2, err = testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    sql.NullString{String: newEmail, Valid: true},
+		Username: user1.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newEmail, user2.Email)
+	require.False(t, user2.IsEmailVerified)
+
+	// Another user's email cannot be taken.
+	other := createRandomUser(t)
+	_, err = testQueries.UpdateUser(context.Background(), UpdateUserParams{
+		Email:    sql.NullString{String: other.Email, Valid: true},
+		Username: user1.Username,
+	})
+	require.Error(t, err)
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	user2, err := testQueries.GetUserByEmail(context.Background(), user1.Email)
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+
+	_, err = testQueries.GetUserByEmail(context.Background(), utils.RandomEmail())
+	require.ErrorIs(t, err, sql.ErrNoRows)
 }
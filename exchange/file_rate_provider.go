@@ -0,0 +1,84 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// rateFile is the on-disk format read by FileRateProvider:
+//
+//	{"updated_at": "2023-06-01T12:00:00Z", "rates": {"USD/EUR": "0.92"}}
+type rateFile struct {
+	UpdatedAt time.Time         `json:"updated_at"`
+	Rates     map[string]string `json:"rates"`
+}
+
+// FileRateProvider serves rates from a JSON file and reloads it whenever
+// the file changes on disk.
+type FileRateProvider struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	table   *StaticRateProvider
+}
+
+func NewFileRateProvider(path string) (*FileRateProvider, error) {
+	provider := &FileRateProvider{path: path}
+	if _, err := provider.load(); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+func (p *FileRateProvider) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	table, err := p.load()
+	if err != nil {
+		return Rate{}, err
+	}
+	return table.Rate(ctx, from, to)
+}
+
+// load returns the current table, re-reading the file if it was modified
+// since the last read.
+func (p *FileRateProvider) load() (*StaticRateProvider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot stat exchange rates file: %w", err)
+	}
+
+	if p.table != nil && info.ModTime().Equal(p.modTime) {
+		return p.table, nil
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read exchange rates file: %w", err)
+	}
+
+	var file rateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("cannot parse exchange rates file: %w", err)
+	}
+
+	updatedAt := file.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = info.ModTime()
+	}
+
+	table, err := NewStaticRateProvider(file.Rates, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	p.table = table
+	p.modTime = info.ModTime()
+	return table, nil
+}
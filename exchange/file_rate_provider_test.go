@@ -0,0 +1,52 @@
+package exchange
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRateProvider(t *testing.T) {
+	provider, err := NewFileRateProvider(filepath.Join("testdata", "rates.json"))
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, "1.3500000000", rate.String())
+	require.Equal(t, time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC), rate.UpdatedAt.UTC())
+
+	_, err = provider.Rate(context.Background(), "EUR", "CAD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestFileRateProviderReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"rates": {"USD/EUR": "0.9"}}`), 0o600)
+	require.NoError(t, err)
+
+	provider, err := NewFileRateProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "0.9000000000", rate.String())
+
+	err = os.WriteFile(path, []byte(`{"rates": {"USD/EUR": "0.95"}}`), 0o600)
+	require.NoError(t, err)
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	rate, err = provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "0.9500000000", rate.String())
+	require.WithinDuration(t, later, rate.UpdatedAt, time.Second)
+}
+
+func TestFileRateProviderMissingFile(t *testing.T) {
+	_, err := NewFileRateProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrRateNotFound = errors.New("exchange rate not found")
+	ErrInvalidRate  = errors.New("exchange rate must be a positive decimal")
+	ErrAmountRange  = errors.New("converted amount is out of range")
+)
+
+// rateScale is the number of decimal places kept when a rate is stored.
+const rateScale = 10
+
+type ExchangeRateProvider interface {
+	// Rate returns how many units of to one unit of from buys.
+	Rate(ctx context.Context, from string, to string) (Rate, error)
+}
+
+type Rate struct {
+	From      string
+	To        string
+	Value     *big.Rat
+	UpdatedAt time.Time
+}
+
+// ParseRate parses a decimal such as "0.92" into a rate value.
+func ParseRate(value string) (*big.Rat, error) {
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok || rat.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, value)
+	}
+	return rat, nil
+}
+
+// String formats the rate the way it is stored on a transfer.
+func (r Rate) String() string {
+	return r.Value.FloatString(rateScale)
+}
+
+// Convert turns an amount in minor units of From into minor units of To,
+// rounding half away from zero.
+func (r Rate) Convert(amount int64) (int64, error) {
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), r.Value)
+
+	num := new(big.Int).Mul(converted.Num(), big.NewInt(2))
+	num.Add(num, converted.Denom())
+	if converted.Sign() < 0 {
+		num.Sub(num, new(big.Int).Mul(converted.Denom(), big.NewInt(2)))
+	}
+	result := num.Quo(num, new(big.Int).Mul(converted.Denom(), big.NewInt(2)))
+
+	if !result.IsInt64() {
+		return 0, ErrAmountRange
+	}
+	return result.Int64(), nil
+}
+
+// ForMinorUnits scales the rate so that Convert maps minor units of From to
+// minor units of To, given each currency's number of minor-unit digits. The
+// scaled rate is only meant for conversion; store the original.
+func (r Rate) ForMinorUnits(fromExponent int, toExponent int) Rate {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExponent-fromExponent))), nil)
+	factor := new(big.Rat).SetInt(scale)
+	if toExponent < fromExponent {
+		factor.Inv(factor)
+	}
+
+	scaled := r
+	scaled.Value = new(big.Rat).Mul(r.Value, factor)
+	return scaled
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// invert returns the rate for the opposite direction.
+func (r Rate) invert() Rate {
+	return Rate{
+		From:      r.To,
+		To:        r.From,
+		Value:     new(big.Rat).Inv(r.Value),
+		UpdatedAt: r.UpdatedAt,
+	}
+}
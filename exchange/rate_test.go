@@ -0,0 +1,79 @@
+package exchange
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("0.92")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(23, 25), rate)
+
+	for _, value := range []string{"", "abc", "0", "-1.5"} {
+		_, err := ParseRate(value)
+		require.ErrorIs(t, err, ErrInvalidRate)
+	}
+}
+
+func TestRateConvert(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rate     string
+		amount   int64
+		expected int64
+	}{
+		{name: "Exact", rate: "0.92", amount: 100, expected: 92},
+		{name: "RoundDown", rate: "1.35", amount: 11, expected: 15},
+		{name: "RoundHalfUp", rate: "0.5", amount: 5, expected: 3},
+		{name: "RoundHalfAwayFromZero", rate: "0.5", amount: -5, expected: -3},
+		{name: "Identity", rate: "1", amount: 12345, expected: 12345},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := ParseRate(tc.rate)
+			require.NoError(t, err)
+
+			converted, err := Rate{Value: value}.Convert(tc.amount)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+}
+
+func TestRateForMinorUnits(t *testing.T) {
+	value, err := ParseRate("150")
+	require.NoError(t, err)
+	usdToJPY := Rate{From: "USD", To: "JPY", Value: value}
+
+	// 12.34 USD buys 1851 JPY.
+	converted, err := usdToJPY.ForMinorUnits(2, 0).Convert(1234)
+	require.NoError(t, err)
+	require.Equal(t, int64(1851), converted)
+
+	// 1851 JPY buys 12.34 USD.
+	converted, err = usdToJPY.invert().ForMinorUnits(0, 2).Convert(1851)
+	require.NoError(t, err)
+	require.Equal(t, int64(1234), converted)
+
+	require.Equal(t, "150.0000000000", usdToJPY.String())
+}
+
+func TestRateConvertOutOfRange(t *testing.T) {
+	value, err := ParseRate("2")
+	require.NoError(t, err)
+
+	_, err = Rate{Value: value}.Convert(1 << 62)
+	require.ErrorIs(t, err, ErrAmountRange)
+}
+
+func TestRateString(t *testing.T) {
+	value, err := ParseRate("0.92")
+	require.NoError(t, err)
+	require.Equal(t, "0.9200000000", Rate{Value: value}.String())
+}
@@ -0,0 +1,62 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// StaticRateProvider serves rates from a fixed table. A rate for USD/EUR
+// also answers EUR/USD with its inverse.
+type StaticRateProvider struct {
+	rates     map[string]*big.Rat
+	updatedAt time.Time
+}
+
+// NewStaticRateProvider builds a provider from a table keyed by currency
+// pair, e.g. {"USD/EUR": "0.92"}.
+func NewStaticRateProvider(rates map[string]string, updatedAt time.Time) (*StaticRateProvider, error) {
+	provider := &StaticRateProvider{
+		rates:     make(map[string]*big.Rat, len(rates)),
+		updatedAt: updatedAt,
+	}
+
+	for pair, value := range rates {
+		from, to, ok := strings.Cut(pair, "/")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid currency pair %q, must look like USD/EUR", pair)
+		}
+
+		rate, err := ParseRate(value)
+		if err != nil {
+			return nil, fmt.Errorf("pair %s: %w", pair, err)
+		}
+
+		provider.rates[pairKey(from, to)] = rate
+	}
+
+	return provider, nil
+}
+
+func (p *StaticRateProvider) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	if from == to {
+		return Rate{From: from, To: to, Value: big.NewRat(1, 1), UpdatedAt: p.updatedAt}, nil
+	}
+
+	if value, ok := p.rates[pairKey(from, to)]; ok {
+		return Rate{From: from, To: to, Value: value, UpdatedAt: p.updatedAt}, nil
+	}
+
+	if value, ok := p.rates[pairKey(to, from)]; ok {
+		rate := Rate{From: to, To: from, Value: value, UpdatedAt: p.updatedAt}
+		return rate.invert(), nil
+	}
+
+	return Rate{}, fmt.Errorf("%w for %s/%s", ErrRateNotFound, from, to)
+}
+
+func pairKey(from string, to string) string {
+	return from + "/" + to
+}
@@ -0,0 +1,43 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticRateProvider(t *testing.T) {
+	updatedAt := time.Now()
+	provider, err := NewStaticRateProvider(map[string]string{"USD/EUR": "0.8"}, updatedAt)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "USD", rate.From)
+	require.Equal(t, "EUR", rate.To)
+	require.Equal(t, "0.8000000000", rate.String())
+	require.Equal(t, updatedAt, rate.UpdatedAt)
+
+	rate, err = provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, "EUR", rate.From)
+	require.Equal(t, "USD", rate.To)
+	require.Equal(t, "1.2500000000", rate.String())
+
+	rate, err = provider.Rate(context.Background(), "CAD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, "1.0000000000", rate.String())
+
+	_, err = provider.Rate(context.Background(), "USD", "CAD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestStaticRateProviderInvalidTable(t *testing.T) {
+	_, err := NewStaticRateProvider(map[string]string{"USDEUR": "0.8"}, time.Now())
+	require.Error(t, err)
+
+	_, err = NewStaticRateProvider(map[string]string{"USD/EUR": "-0.8"}, time.Now())
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
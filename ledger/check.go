@@ -0,0 +1,103 @@
+// Package ledger verifies the invariants of the double-entry ledger.
+package ledger
+
+import (
+	"context"
+	"time"
+
+	db "github.com/wenealves10/gobank/db/sqlc"
+)
+
+// AccountDiscrepancy is an account whose balance differs from the sum of
+// its entries.
+type AccountDiscrepancy struct {
+	AccountID    int64  `json:"account_id"`
+	Currency     string `json:"currency"`
+	Balance      int64  `json:"balance"`
+	EntriesTotal int64  `json:"entries_total"`
+	// Difference is how much the balance exceeds its entries by.
+	Difference int64 `json:"difference"`
+}
+
+// JournalDiscrepancy is a ledger transaction whose entries in one currency
+// do not net to zero.
+type JournalDiscrepancy struct {
+	LedgerTransactionID int64  `json:"ledger_transaction_id"`
+	Currency            string `json:"currency"`
+	Total               int64  `json:"total"`
+}
+
+// Adjustment is a correcting entry written by the reconciler.
+type Adjustment struct {
+	AccountID           int64 `json:"account_id"`
+	LedgerTransactionID int64 `json:"ledger_transaction_id"`
+	Amount              int64 `json:"amount"`
+}
+
+// Report is the outcome of a ledger check.
+type Report struct {
+	CheckedAt            time.Time            `json:"checked_at"`
+	AccountDiscrepancies []AccountDiscrepancy `json:"account_discrepancies"`
+	JournalDiscrepancies []JournalDiscrepancy `json:"journal_discrepancies"`
+	Adjustments          []Adjustment         `json:"adjustments,omitempty"`
+}
+
+// OK reports whether the ledger is consistent, counting account
+// discrepancies that were adjusted as resolved.
+func (r Report) OK() bool {
+	if len(r.JournalDiscrepancies) > 0 {
+		return false
+	}
+
+	adjusted := make(map[int64]bool, len(r.Adjustments))
+	for _, adjustment := range r.Adjustments {
+		adjusted[adjustment.AccountID] = true
+	}
+
+	for _, discrepancy := range r.AccountDiscrepancies {
+		if !adjusted[discrepancy.AccountID] {
+			return false
+		}
+	}
+	return true
+}
+
+// Check verifies that every account balance equals the sum of its entries
+// and that every journal nets to zero in each currency.
+func Check(ctx context.Context, q db.Querier) (Report, error) {
+	report := Report{
+		CheckedAt:            time.Now(),
+		AccountDiscrepancies: []AccountDiscrepancy{},
+		JournalDiscrepancies: []JournalDiscrepancy{},
+	}
+
+	accounts, err := q.ListAccountBalanceMismatches(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, row := range accounts {
+		report.AccountDiscrepancies = append(report.AccountDiscrepancies, AccountDiscrepancy{
+			AccountID:    row.AccountID,
+			Currency:     row.Currency,
+			Balance:      row.Balance,
+			EntriesTotal: row.EntriesTotal,
+			Difference:   row.Balance - row.EntriesTotal,
+		})
+	}
+
+	journals, err := q.ListUnbalancedLedgerTransactions(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, row := range journals {
+		report.JournalDiscrepancies = append(report.JournalDiscrepancies, JournalDiscrepancy{
+			LedgerTransactionID: row.LedgerTransactionID,
+			Currency:            row.Currency,
+			Total:               row.Total,
+		})
+	}
+
+	return report, nil
+}
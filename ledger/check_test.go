@@ -0,0 +1,79 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAccountBalanceMismatches(gomock.Any()).
+		Times(1).
+		Return([]db.ListAccountBalanceMismatchesRow{
+			{AccountID: 1, Currency: utils.USD, Balance: 100, EntriesTotal: 90},
+		}, nil)
+	store.EXPECT().
+		ListUnbalancedLedgerTransactions(gomock.Any()).
+		Times(1).
+		Return([]db.ListUnbalancedLedgerTransactionsRow{
+			{LedgerTransactionID: 7, Currency: utils.EUR, Total: -5},
+		}, nil)
+
+	report, err := Check(context.Background(), store)
+	require.NoError(t, err)
+	require.False(t, report.OK())
+	require.NotZero(t, report.CheckedAt)
+	require.Equal(t, []AccountDiscrepancy{
+		{AccountID: 1, Currency: utils.USD, Balance: 100, EntriesTotal: 90, Difference: 10},
+	}, report.AccountDiscrepancies)
+	require.Equal(t, []JournalDiscrepancy{
+		{LedgerTransactionID: 7, Currency: utils.EUR, Total: -5},
+	}, report.JournalDiscrepancies)
+}
+
+func TestCheckBalanced(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAccountBalanceMismatches(gomock.Any()).
+		Times(1).
+		Return([]db.ListAccountBalanceMismatchesRow{}, nil)
+	store.EXPECT().
+		ListUnbalancedLedgerTransactions(gomock.Any()).
+		Times(1).
+		Return([]db.ListUnbalancedLedgerTransactionsRow{}, nil)
+
+	report, err := Check(context.Background(), store)
+	require.NoError(t, err)
+	require.True(t, report.OK())
+}
+
+func TestCheckError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		ListAccountBalanceMismatches(gomock.Any()).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+	store.EXPECT().
+		ListUnbalancedLedgerTransactions(gomock.Any()).
+		Times(0)
+
+	_, err := Check(context.Background(), store)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
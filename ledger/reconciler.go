@@ -0,0 +1,103 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/wenealves10/gobank/db/sqlc"
+)
+
+// adjustmentDescription is written on the entries of every adjustment.
+const adjustmentDescription = "balance reconciliation"
+
+// Reconciler compares account balances with the entries behind them and,
+// when allowed to fix, writes adjustment entries for the accounts that
+// drifted.
+type Reconciler struct {
+	store    db.Store
+	interval time.Duration
+	fix      bool
+}
+
+// NewReconciler creates a reconciler. The interval is only used by Start.
+func NewReconciler(store db.Store, interval time.Duration, fix bool) *Reconciler {
+	return &Reconciler{
+		store:    store,
+		interval: interval,
+		fix:      fix,
+	}
+}
+
+// Reconcile checks the ledger once and, when fixing, adjusts every account
+// whose balance differs from its entries. Drift on the system cash accounts
+// is reported but never adjusted.
+func (r *Reconciler) Reconcile(ctx context.Context) (Report, error) {
+	report, err := Check(ctx, r.store)
+	if err != nil || !r.fix {
+		return report, err
+	}
+
+	for _, discrepancy := range report.AccountDiscrepancies {
+		result, err := r.store.ReconcileAccountTx(ctx, db.ReconcileAccountTxParams{
+			AccountID:   discrepancy.AccountID,
+			Description: adjustmentDescription,
+		})
+		if err != nil {
+			if errors.Is(err, db.ErrSystemAccount) {
+				continue
+			}
+			return report, err
+		}
+
+		if result.Drift == 0 {
+			continue
+		}
+
+		report.Adjustments = append(report.Adjustments, Adjustment{
+			AccountID:           discrepancy.AccountID,
+			LedgerTransactionID: result.LedgerTransaction.ID,
+			Amount:              result.Drift,
+		})
+	}
+
+	return report, nil
+}
+
+// Start reconciles every interval until ctx is done, logging the reports
+// that found discrepancies. Servers start reconcilers that only report:
+// writing adjustments is left to an operator running ledger reconcile -fix.
+func (r *Reconciler) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reconcileAndLog(ctx)
+		}
+	}
+}
+
+func (r *Reconciler) reconcileAndLog(ctx context.Context) {
+	report, err := r.Reconcile(ctx)
+	if err != nil {
+		log.Println("cannot reconcile ledger:", err)
+		return
+	}
+
+	if len(report.AccountDiscrepancies) == 0 && len(report.JournalDiscrepancies) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Println("cannot encode ledger report:", err)
+		return
+	}
+	log.Printf("ledger reconciliation found discrepancies: %s", data)
+}
@@ -0,0 +1,123 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReconcile(t *testing.T) {
+	mismatches := []db.ListAccountBalanceMismatchesRow{
+		{AccountID: 1, Currency: utils.USD, Balance: 100, EntriesTotal: 90},
+		{AccountID: 2, Currency: utils.USD, Balance: -50, EntriesTotal: -40},
+	}
+
+	testCases := []struct {
+		name          string
+		fix           bool
+		buildStubs    func(store *mocks.MockStore)
+		checkResponse func(t *testing.T, report Report, err error)
+	}{
+		{
+			name: "ReportOnly",
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					ReconcileAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.NoError(t, err)
+				require.Len(t, report.AccountDiscrepancies, 2)
+				require.Empty(t, report.Adjustments)
+				require.False(t, report.OK())
+			},
+		},
+		{
+			name: "Fix",
+			fix:  true,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					ReconcileAccountTx(gomock.Any(), gomock.Eq(db.ReconcileAccountTxParams{
+						AccountID:   1,
+						Description: adjustmentDescription,
+					})).
+					Times(1).
+					Return(db.ReconcileAccountTxResult{
+						Drift:             10,
+						LedgerTransaction: db.LedgerTransaction{ID: 11},
+					}, nil)
+				// The second account is a cash account.
+				store.EXPECT().
+					ReconcileAccountTx(gomock.Any(), gomock.Eq(db.ReconcileAccountTxParams{
+						AccountID:   2,
+						Description: adjustmentDescription,
+					})).
+					Times(1).
+					Return(db.ReconcileAccountTxResult{}, db.ErrSystemAccount)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []Adjustment{
+					{AccountID: 1, LedgerTransactionID: 11, Amount: 10},
+				}, report.Adjustments)
+				require.False(t, report.OK())
+			},
+		},
+		{
+			name: "FixError",
+			fix:  true,
+			buildStubs: func(store *mocks.MockStore) {
+				store.EXPECT().
+					ReconcileAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReconcileAccountTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			store.EXPECT().
+				ListAccountBalanceMismatches(gomock.Any()).
+				Times(1).
+				Return(mismatches, nil)
+			store.EXPECT().
+				ListUnbalancedLedgerTransactions(gomock.Any()).
+				Times(1).
+				Return([]db.ListUnbalancedLedgerTransactionsRow{}, nil)
+			tc.buildStubs(store)
+
+			reconciler := NewReconciler(store, 0, tc.fix)
+			report, err := reconciler.Reconcile(context.Background())
+			tc.checkResponse(t, report, err)
+		})
+	}
+}
+
+func TestReportOK(t *testing.T) {
+	report := Report{
+		AccountDiscrepancies: []AccountDiscrepancy{{AccountID: 1, Difference: 10}},
+	}
+	require.False(t, report.OK())
+
+	report.Adjustments = []Adjustment{{AccountID: 1, Amount: 10}}
+	require.True(t, report.OK())
+
+	report.JournalDiscrepancies = []JournalDiscrepancy{{LedgerTransactionID: 1, Total: 5}}
+	require.False(t, report.OK())
+}
@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileMailer appends every email to a file instead of delivering it, which
+// is handy in development.
+type FileMailer struct {
+	path string
+	from string
+
+	mu sync.Mutex
+}
+
+func NewFileMailer(path string, from string) *FileMailer {
+	return &FileMailer{
+		path: path,
+		from: from,
+	}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	data, err := msg.format(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(data, "\r\n"...)); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,30 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	mailer := NewFileMailer(path, "bank@example.com")
+
+	for _, subject := range []string{"First", "Second"} {
+		err := mailer.Send(context.Background(), Message{
+			To:      []string{"alice@example.com"},
+			Subject: subject,
+			Body:    "Hello",
+		})
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(string(data), "From: bank@example.com\r\n"))
+	require.Less(t, strings.Index(string(data), "Subject: First"), strings.Index(string(data), "Subject: Second"))
+}
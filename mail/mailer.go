@@ -0,0 +1,56 @@
+// Package mail sends the emails users receive from the bank, such as email
+// verification and password reset codes.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidHeader = errors.New("email header must not contain line breaks")
+
+type Mailer interface {
+	// Send delivers msg to every recipient in msg.To.
+	Send(ctx context.Context, msg Message) error
+}
+
+type Message struct {
+	To      []string
+	Subject string
+	// Body is sent as plain text.
+	Body string
+}
+
+// format renders msg as an RFC 5322 message sent from from. It refuses
+// headers with line breaks, which would let their content inject headers
+// of its own.
+func (msg Message) format(from string, date time.Time) ([]byte, error) {
+	if len(msg.To) == 0 {
+		return nil, errors.New("email has no recipients")
+	}
+
+	headers := []string{from, msg.Subject}
+	headers = append(headers, msg.To...)
+	for _, header := range headers {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+
+	return buf.Bytes(), nil
+}
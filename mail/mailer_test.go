@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageFormat(t *testing.T) {
+	msg := Message{
+		To:      []string{"alice@example.com", "bob@example.com"},
+		Subject: "Verify your email",
+		Body:    "Your code is 1234.\nIt expires in a day.",
+	}
+
+	date := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	data, err := msg.format("bank@example.com", date)
+	require.NoError(t, err)
+
+	text := string(data)
+	require.True(t, strings.HasPrefix(text, "From: bank@example.com\r\n"))
+	require.Contains(t, text, "To: alice@example.com, bob@example.com\r\n")
+	require.Contains(t, text, "Subject: Verify your email\r\n")
+	require.Contains(t, text, "Date: Thu, 01 Jun 2023 12:00:00 +0000\r\n")
+	require.Contains(t, text, "\r\n\r\nYour code is 1234.\r\nIt expires in a day.\r\n")
+}
+
+func TestMessageFormatRejectsHeaderInjection(t *testing.T) {
+	for _, msg := range []Message{
+		{To: []string{"alice@example.com"}, Subject: "Hi\r\nBcc: eve@example.com"},
+		{To: []string{"alice@example.com\nBcc: eve@example.com"}, Subject: "Hi"},
+	} {
+		_, err := msg.format("bank@example.com", time.Now())
+		require.ErrorIs(t, err, ErrInvalidHeader)
+	}
+
+	_, err := Message{Subject: "Hi"}.format("bank@example.com", time.Now())
+	require.Error(t, err)
+}
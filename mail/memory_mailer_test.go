@@ -0,0 +1,21 @@
+package mail
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryMailer(t *testing.T) {
+	mailer := NewMemoryMailer()
+	require.Empty(t, mailer.Messages())
+
+	msg := Message{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hello"}
+	require.NoError(t, mailer.Send(context.Background(), msg))
+	require.Equal(t, []Message{msg}, mailer.Messages())
+
+	err := mailer.Send(context.Background(), Message{To: []string{"alice@example.com"}, Subject: "Hi\nBcc: eve@example.com"})
+	require.ErrorIs(t, err, ErrInvalidHeader)
+	require.Len(t, mailer.Messages(), 1)
+}
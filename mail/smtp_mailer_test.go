@@ -0,0 +1,121 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSession is what a fakeSMTPServer was told by its single client.
+type smtpSession struct {
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTPServer accepts one connection speaking just enough SMTP for
+// SMTPMailer, without STARTTLS or authentication, and returns its address.
+func fakeSMTPServer(t *testing.T) (string, <-chan smtpSession) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var session smtpSession
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(line, "MAIL FROM:"):
+				session.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+				reply("250 OK")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				session.to = append(session.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+				reply("250 OK")
+			case line == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				session.data = data.String()
+				reply("250 OK")
+			case line == "QUIT":
+				reply("221 Bye")
+				sessions <- session
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), sessions
+}
+
+func TestSMTPMailer(t *testing.T) {
+	addr, sessions := fakeSMTPServer(t)
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+
+	portNumber, err := net.LookupPort("tcp", port)
+	require.NoError(t, err)
+
+	mailer := NewSMTPMailer(host, portNumber, "", "", "bank@example.com")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = mailer.Send(ctx, Message{
+		To:      []string{"alice@example.com", "bob@example.com"},
+		Subject: "Verify your email",
+		Body:    "Your code is 1234.",
+	})
+	require.NoError(t, err)
+
+	session := <-sessions
+	require.Equal(t, "bank@example.com", session.from)
+	require.Equal(t, []string{"alice@example.com", "bob@example.com"}, session.to)
+	require.Contains(t, session.data, "Subject: Verify your email\r\n")
+	require.Contains(t, session.data, "Your code is 1234.\r\n")
+}
+
+func TestSMTPMailerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+
+	mailer := NewSMTPMailer("127.0.0.1", addr.Port, "", "", "bank@example.com")
+	err = mailer.Send(context.Background(), Message{To: []string{"alice@example.com"}, Subject: "Hi"})
+	require.Error(t, err)
+}
@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
 
 	_ "github.com/lib/pq"
 	"github.com/wenealves10/gobank/api"
 	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/ledger"
+	"github.com/wenealves10/gobank/scheduler"
+	"github.com/wenealves10/gobank/token"
 	"github.com/wenealves10/gobank/utils"
 )
 
@@ -22,6 +32,27 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
+
+	if len(os.Args) > 1 {
+		runCommand(store, os.Args[1:])
+		return
+	}
+
+	err = loadCurrencies(config, store)
+	if err != nil {
+		log.Fatal("cannot load currencies:", err)
+	}
+
+	if config.ReconcileInterval > 0 {
+		reconciler := ledger.NewReconciler(store, config.ReconcileInterval, false)
+		go reconciler.Start(context.Background())
+	}
+
+	if config.SchedulerInterval > 0 {
+		worker := scheduler.NewWorker(store, config.SchedulerInterval)
+		go worker.Start(context.Background())
+	}
+
 	server, err := api.NewServer(config, store)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
@@ -32,3 +63,135 @@ func main() {
 		log.Fatal("cannot start server", err)
 	}
 }
+
+// runCommand runs an administrative command instead of the server.
+func runCommand(store db.Store, args []string) {
+	if len(args) == 3 && args[0] == "token" && args[1] == "genkey" {
+		generateSigningKey(args[2])
+		return
+	}
+
+	if len(args) == 2 && args[0] == "audit" && args[1] == "verify" {
+		os.Exit(verifyAuditLog(store))
+	}
+
+	if len(args) < 2 || args[0] != "ledger" {
+		log.Fatalf("unknown command %q", strings.Join(args, " "))
+	}
+
+	switch args[1] {
+	case "check":
+		report, err := ledger.Check(context.Background(), store)
+		os.Exit(writeReport(report, err))
+	case "reconcile":
+		flags := flag.NewFlagSet("ledger reconcile", flag.ExitOnError)
+		fix := flags.Bool("fix", false, "write adjustment entries for accounts whose balance drifted")
+		flags.Parse(args[2:])
+
+		report, err := ledger.NewReconciler(store, 0, *fix).Reconcile(context.Background())
+		os.Exit(writeReport(report, err))
+	default:
+		log.Fatalf("unknown command %q", strings.Join(args, " "))
+	}
+}
+
+// generateSigningKey prints a new token signing key and the verification
+// key other replicas need to accept its tokens during a rotation.
+func generateSigningKey(id string) {
+	signingKey, verificationKey, err := token.GenerateSigningKey(id)
+	if err != nil {
+		log.Fatal("cannot generate signing key:", err)
+	}
+
+	fmt.Printf("TOKEN_SIGNING_KEY=%s\n", signingKey)
+	fmt.Printf("# add to TOKEN_VERIFICATION_KEYS while rotating:\n# %s\n", verificationKey)
+}
+
+// verifyAuditLog checks the hash chain of the audit log and returns the exit
+// status, which is 1 when an event does not match it.
+func verifyAuditLog(store db.Store) int {
+	result, err := db.VerifyAuditChain(context.Background(), store)
+
+	var chainErr *db.AuditChainError
+	if errors.As(err, &chainErr) {
+		log.Println(err)
+		return 1
+	}
+
+	if err != nil {
+		log.Fatal("cannot verify audit log:", err)
+	}
+
+	fmt.Printf("%d audit events verified, last hash %s\n", result.Events, result.LastHash)
+	return 0
+}
+
+// writeReport writes a ledger report to stdout as JSON and returns the exit
+// status, which is 1 when discrepancies remain.
+func writeReport(report ledger.Report, err error) int {
+	if err != nil {
+		log.Fatal("cannot check ledger:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal("cannot write ledger report:", err)
+	}
+
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// loadCurrencies fills the currency registry from config when set, and from
+// the currencies table otherwise. Configured currencies are written to the
+// table, which accounts and scheduled transfers reference.
+func loadCurrencies(config utils.Config, store db.Store) error {
+	if config.Currencies != "" {
+		currencies, err := utils.ParseCurrencies(config.Currencies)
+		if err != nil {
+			return err
+		}
+
+		for _, currency := range currencies {
+			row, err := store.UpsertCurrency(context.Background(), db.UpsertCurrencyParams{
+				Code:     currency.Code,
+				Exponent: int32(currency.Exponent),
+				Symbol:   currency.Symbol,
+				Enabled:  currency.Enabled,
+			})
+			if err != nil {
+				return fmt.Errorf("cannot save currency %s: %w", currency.Code, err)
+			}
+
+			// Amounts are stored in minor units, so the exponent of a
+			// currency in use cannot change.
+			if int(row.Exponent) != currency.Exponent {
+				return fmt.Errorf("currency %s has exponent %d in the currencies table, not %d", currency.Code, row.Exponent, currency.Exponent)
+			}
+		}
+
+		utils.Currencies.Replace(currencies)
+		return nil
+	}
+
+	rows, err := store.ListCurrencies(context.Background())
+	if err != nil {
+		return err
+	}
+
+	currencies := make([]utils.Currency, len(rows))
+	for i, row := range rows {
+		currencies[i] = utils.Currency{
+			Code:     row.Code,
+			Exponent: int(row.Exponent),
+			Symbol:   row.Symbol,
+			Enabled:  row.Enabled,
+		}
+	}
+
+	utils.Currencies.Replace(currencies)
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package scheduler executes scheduled and recurring transfers.
+package scheduler
+
+import "time"
+
+// How often a scheduled transfer runs.
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Statuses of a scheduled transfer. Only active ones are executed.
+const (
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// NextRunAt returns the first run of a schedule starting at startAt that is
+// due strictly after after. Runs missed while the schedule was paused or the
+// workers were down are skipped rather than paid all at once. It returns
+// false when the schedule has no further runs.
+func NextRunAt(frequency string, startAt time.Time, after time.Time) (time.Time, bool) {
+	var run func(n int) time.Time
+
+	switch frequency {
+	case FrequencyDaily:
+		run = func(n int) time.Time { return startAt.AddDate(0, 0, n) }
+	case FrequencyWeekly:
+		run = func(n int) time.Time { return startAt.AddDate(0, 0, 7*n) }
+	case FrequencyMonthly:
+		run = func(n int) time.Time { return addMonths(startAt, n) }
+	default:
+		return time.Time{}, false
+	}
+
+	// Start just short of the answer so that long-running schedules do not
+	// walk through every past run.
+	n := 0
+	if after.After(startAt) {
+		switch frequency {
+		case FrequencyDaily:
+			n = int(after.Sub(startAt)/(24*time.Hour)) - 1
+		case FrequencyWeekly:
+			n = int(after.Sub(startAt)/(7*24*time.Hour)) - 1
+		case FrequencyMonthly:
+			n = (after.Year()-startAt.Year())*12 + int(after.Month()-startAt.Month()) - 1
+		}
+		if n < 0 {
+			n = 0
+		}
+	}
+
+	for !run(n).After(after) {
+		n++
+	}
+	return run(n), true
+}
+
+// addMonths moves t by a number of months, keeping its day of the month but
+// clamping it to the end of shorter months, so a schedule started on the
+// 31st runs on April 30th rather than May 1st.
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+	if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRunAt(t *testing.T) {
+	startAt := time.Date(2023, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		frequency string
+		after     time.Time
+		expected  time.Time
+		ok        bool
+	}{
+		{
+			name:      "Once",
+			frequency: FrequencyOnce,
+			after:     startAt,
+			ok:        false,
+		},
+		{
+			name:      "DailyBeforeStart",
+			frequency: FrequencyDaily,
+			after:     startAt.Add(-time.Hour),
+			expected:  startAt,
+			ok:        true,
+		},
+		{
+			name:      "DailyAtRun",
+			frequency: FrequencyDaily,
+			after:     startAt,
+			expected:  time.Date(2023, time.February, 1, 9, 0, 0, 0, time.UTC),
+			ok:        true,
+		},
+		{
+			name:      "DailySkipsMissedRuns",
+			frequency: FrequencyDaily,
+			after:     time.Date(2023, time.March, 10, 12, 0, 0, 0, time.UTC),
+			expected:  time.Date(2023, time.March, 11, 9, 0, 0, 0, time.UTC),
+			ok:        true,
+		},
+		{
+			name:      "Weekly",
+			frequency: FrequencyWeekly,
+			after:     time.Date(2023, time.February, 7, 9, 0, 0, 0, time.UTC),
+			expected:  time.Date(2023, time.February, 14, 9, 0, 0, 0, time.UTC),
+			ok:        true,
+		},
+		{
+			name:      "MonthlyClampsToMonthEnd",
+			frequency: FrequencyMonthly,
+			after:     startAt,
+			expected:  time.Date(2023, time.February, 28, 9, 0, 0, 0, time.UTC),
+			ok:        true,
+		},
+		{
+			name:      "MonthlyKeepsDayOfMonth",
+			frequency: FrequencyMonthly,
+			after:     time.Date(2023, time.February, 28, 9, 0, 0, 0, time.UTC),
+			expected:  time.Date(2023, time.March, 31, 9, 0, 0, 0, time.UTC),
+			ok:        true,
+		},
+		{
+			name:      "MonthlyAcrossYears",
+			frequency: FrequencyMonthly,
+			after:     time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC),
+			expected:  time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC),
+			ok:        true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			next, ok := NextRunAt(tc.frequency, startAt, tc.after)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, next)
+		})
+	}
+}
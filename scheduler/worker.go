@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/wenealves10/gobank/db/sqlc"
+)
+
+const (
+	// batchSize is how many due schedules a worker claims at a time.
+	batchSize = 50
+	// leaseDuration is how long a claimed schedule stays hidden from other
+	// workers. A worker that dies mid-run loses its claim after this.
+	leaseDuration = 5 * time.Minute
+	// maxAttempts is how many times a run is tried before the schedule is
+	// marked as failed.
+	maxAttempts = 5
+)
+
+// Worker executes due scheduled transfers. Any number of workers may run
+// against the same database: each claims its schedules with a lease taken
+// under SKIP LOCKED, and every run advances its schedule in the transaction
+// of its transfer, so a run that is retried after its lease expired is
+// never paid twice.
+type Worker struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewWorker creates a worker polling for due schedules every interval.
+func NewWorker(store db.Store, interval time.Duration) *Worker {
+	return &Worker{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start runs due schedules every interval until ctx is done.
+func (w *Worker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.RunDue(ctx); err != nil {
+				log.Println("cannot run scheduled transfers:", err)
+			}
+		}
+	}
+}
+
+// RunDue claims the schedules that are due and executes them, returning how
+// many were claimed.
+func (w *Worker) RunDue(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	schedules, err := w.store.ClaimDueScheduledTransfers(ctx, db.ClaimDueScheduledTransfersParams{
+		LockedUntil: sql.NullTime{Time: now.Add(leaseDuration), Valid: true},
+		Now:         now,
+		BatchSize:   batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, schedule := range schedules {
+		// The lease expires on its own, so the run is picked up again
+		// even when its outcome cannot be recorded.
+		if err := w.execute(ctx, schedule); err != nil {
+			log.Printf("cannot record run of scheduled transfer %d: %v", schedule.ID, err)
+		}
+	}
+
+	return len(schedules), nil
+}
+
+func (w *Worker) execute(ctx context.Context, schedule db.ScheduledTransfer) error {
+	// The transfer is audited as made by the owner of the schedule, under
+	// the id of the run.
+	auditCtx := db.WithAuditContext(ctx, db.AuditContext{
+		Actor:     schedule.Owner,
+		RequestID: runID(schedule),
+	})
+
+	status := StatusActive
+	nextRunAt, ok := NextRunAt(schedule.Frequency, schedule.StartAt, time.Now())
+	if !ok {
+		status, nextRunAt = StatusCompleted, schedule.NextRunAt
+	}
+
+	_, err := w.store.RunScheduledTransferTx(auditCtx, db.RunScheduledTransferTxParams{
+		ID:        schedule.ID,
+		Runs:      schedule.Runs,
+		NextRunAt: nextRunAt,
+		Status:    status,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Another worker made the run, or the owner paused the schedule,
+		// and there is nothing left to record.
+		if errors.Is(err, db.ErrScheduledRunDone) {
+			return nil
+		}
+		return w.recordFailure(ctx, schedule, err)
+	}
+
+	return nil
+}
+
+// recordFailure schedules a retry of the current run with exponential
+// backoff, or gives up on the schedule once it ran out of attempts.
+func (w *Worker) recordFailure(ctx context.Context, schedule db.ScheduledTransfer, cause error) error {
+	attempts := schedule.Attempts + 1
+
+	status := StatusActive
+	if attempts >= maxAttempts {
+		status = StatusFailed
+	}
+
+	_, err := w.store.RecordScheduledTransferFailure(ctx, db.RecordScheduledTransferFailureParams{
+		LastError: cause.Error(),
+		NextRunAt: time.Now().Add(retryDelay(attempts)),
+		Status:    status,
+		ID:        schedule.ID,
+		Runs:      schedule.Runs,
+	})
+	return err
+}
+
+// retryDelay doubles from one minute with every failed attempt.
+func retryDelay(attempts int32) time.Duration {
+	return time.Minute << (attempts - 1)
+}
+
+// runID identifies the current run of a schedule in the audit log.
+func runID(schedule db.ScheduledTransfer) string {
+	return fmt.Sprintf("scheduled-transfer:%d:%d", schedule.ID, schedule.Runs)
+}
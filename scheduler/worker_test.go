@@ -0,0 +1,185 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/db/mocks"
+	db "github.com/wenealves10/gobank/db/sqlc"
+	"github.com/wenealves10/gobank/utils"
+	"go.uber.org/mock/gomock"
+)
+
+func randomSchedule(frequency string) db.ScheduledTransfer {
+	startAt := time.Now().Add(-time.Hour)
+
+	return db.ScheduledTransfer{
+		ID:            utils.RandomInt(1, 1000),
+		Owner:         utils.RandomOwner(),
+		FromAccountID: utils.RandomInt(1, 1000),
+		ToAccountID:   utils.RandomInt(1001, 2000),
+		Amount:        utils.RandomInt(1, 1000),
+		Currency:      utils.USD,
+		Frequency:     frequency,
+		StartAt:       startAt,
+		NextRunAt:     startAt,
+		Status:        StatusActive,
+		Runs:          3,
+	}
+}
+
+func TestWorkerRunDue(t *testing.T) {
+	testCases := []struct {
+		name       string
+		schedule   db.ScheduledTransfer
+		buildStubs func(store *mocks.MockStore, schedule db.ScheduledTransfer)
+	}{
+		{
+			name:     "Recurring",
+			schedule: randomSchedule(FrequencyDaily),
+			buildStubs: func(store *mocks.MockStore, schedule db.ScheduledTransfer) {
+				store.EXPECT().
+					RunScheduledTransferTx(gomock.Any(), gomock.Eq(db.RunScheduledTransferTxParams{
+						ID:        schedule.ID,
+						Runs:      schedule.Runs,
+						NextRunAt: schedule.StartAt.AddDate(0, 0, 1),
+						Status:    StatusActive,
+					})).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, _ db.RunScheduledTransferTxParams) (db.RunScheduledTransferTxResult, error) {
+						audit := db.AuditContextFrom(ctx)
+						require.Equal(t, schedule.Owner, audit.Actor)
+						require.Equal(t, runID(schedule), audit.RequestID)
+						return db.RunScheduledTransferTxResult{}, nil
+					})
+
+				store.EXPECT().
+					RecordScheduledTransferFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+		{
+			name:     "Once",
+			schedule: randomSchedule(FrequencyOnce),
+			buildStubs: func(store *mocks.MockStore, schedule db.ScheduledTransfer) {
+				store.EXPECT().
+					RunScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RunScheduledTransferTxParams) (db.RunScheduledTransferTxResult, error) {
+						require.Equal(t, StatusCompleted, arg.Status)
+						require.Equal(t, schedule.NextRunAt, arg.NextRunAt)
+						return db.RunScheduledTransferTxResult{}, nil
+					})
+			},
+		},
+		{
+			name:     "RunDone",
+			schedule: randomSchedule(FrequencyDaily),
+			buildStubs: func(store *mocks.MockStore, schedule db.ScheduledTransfer) {
+				// The run was made by a worker whose lease expired, or the
+				// schedule was paused after it was claimed.
+				store.EXPECT().
+					RunScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RunScheduledTransferTxResult{}, db.ErrScheduledRunDone)
+
+				store.EXPECT().
+					RecordScheduledTransferFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+		{
+			name:     "Retry",
+			schedule: randomSchedule(FrequencyMonthly),
+			buildStubs: func(store *mocks.MockStore, schedule db.ScheduledTransfer) {
+				store.EXPECT().
+					RunScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RunScheduledTransferTxResult{}, db.ErrInsufficientFunds)
+
+				store.EXPECT().
+					RecordScheduledTransferFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RecordScheduledTransferFailureParams) (int64, error) {
+						require.Equal(t, schedule.ID, arg.ID)
+						require.Equal(t, db.ErrInsufficientFunds.Error(), arg.LastError)
+						require.Equal(t, StatusActive, arg.Status)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.NextRunAt, time.Second)
+						return 1, nil
+					})
+			},
+		},
+		{
+			name: "LastAttempt",
+			schedule: func() db.ScheduledTransfer {
+				schedule := randomSchedule(FrequencyWeekly)
+				schedule.Attempts = maxAttempts - 1
+				return schedule
+			}(),
+			buildStubs: func(store *mocks.MockStore, schedule db.ScheduledTransfer) {
+				store.EXPECT().
+					RunScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RunScheduledTransferTxResult{}, db.ErrAccountFrozen)
+
+				store.EXPECT().
+					RecordScheduledTransferFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RecordScheduledTransferFailureParams) (int64, error) {
+						require.Equal(t, StatusFailed, arg.Status)
+						return 1, nil
+					})
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mocks.NewMockStore(ctrl)
+			store.EXPECT().
+				ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.ClaimDueScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
+					require.True(t, arg.LockedUntil.Valid)
+					require.Equal(t, leaseDuration, arg.LockedUntil.Time.Sub(arg.Now))
+					return []db.ScheduledTransfer{tc.schedule}, nil
+				})
+			tc.buildStubs(store, tc.schedule)
+
+			n, err := NewWorker(store, time.Minute).RunDue(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+		})
+	}
+}
+
+func TestWorkerRunDueClaimError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mocks.NewMockStore(ctrl)
+	store.EXPECT().
+		ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, errors.New("connection refused"))
+	store.EXPECT().
+		RunScheduledTransferTx(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	_, err := NewWorker(store, time.Minute).RunDue(context.Background())
+	require.Error(t, err)
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, time.Minute, retryDelay(1))
+	require.Equal(t, 2*time.Minute, retryDelay(2))
+	require.Equal(t, 16*time.Minute, retryDelay(5))
+}
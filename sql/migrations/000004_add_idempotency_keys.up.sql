@@ -0,0 +1,19 @@
+CREATE TABLE "idempotency_keys" (
+  "username" varchar NOT NULL,
+  "idempotency_key" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "transfer_id" bigint NOT NULL,
+  "response" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "idempotency_key")
+);
+
+CREATE INDEX ON "idempotency_keys" ("transfer_id");
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 of the original request body';
+
+COMMENT ON COLUMN "idempotency_keys"."response" IS 'result returned to the original request';
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
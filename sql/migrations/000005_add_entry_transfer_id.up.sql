@@ -0,0 +1,9 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'set when the entry was written by a transfer';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "transfers_created_at_id_idx";
@@ -0,0 +1 @@
+CREATE INDEX ON "transfers" ("created_at", "id");
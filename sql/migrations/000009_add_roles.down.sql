@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "is_frozen";
+ALTER TABLE IF EXISTS "users" DROP CONSTRAINT IF EXISTS "role_supported";
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,26 @@
+ALTER TABLE "transfers" ADD COLUMN "from_currency" varchar;
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+ALTER TABLE "transfers" ADD COLUMN "to_currency" varchar;
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" numeric(20,10) NOT NULL DEFAULT 1;
+ALTER TABLE "transfers" ADD COLUMN "rate_updated_at" timestamptz;
+
+UPDATE "transfers" t
+SET "from_currency" = fa."currency",
+    "to_currency" = ta."currency",
+    "to_amount" = t."amount",
+    "rate_updated_at" = t."created_at"
+FROM "accounts" fa, "accounts" ta
+WHERE fa."id" = t."from_account_id" AND ta."id" = t."to_account_id";
+
+ALTER TABLE "transfers" ALTER COLUMN "from_currency" SET NOT NULL;
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+ALTER TABLE "transfers" ALTER COLUMN "to_currency" SET NOT NULL;
+ALTER TABLE "transfers" ALTER COLUMN "rate_updated_at" SET NOT NULL;
+ALTER TABLE "transfers" ALTER COLUMN "rate_updated_at" SET DEFAULT (now());
+
+ALTER TABLE "transfers" ADD CONSTRAINT "exchange_rate_positive" CHECK ("exchange_rate" > 0);
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive, debited from the source account in from_currency';
+COMMENT ON COLUMN "transfers"."to_amount" IS 'credited to the destination account, in to_currency';
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'units of to_currency bought by one unit of from_currency';
+COMMENT ON COLUMN "transfers"."rate_updated_at" IS 'when the exchange rate was published';
@@ -0,0 +1,21 @@
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "exponent" integer NOT NULL,
+  "symbol" varchar NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "currencies" ADD CONSTRAINT "exponent_range" CHECK ("exponent" BETWEEN 0 AND 4);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+
+COMMENT ON COLUMN "currencies"."exponent" IS 'number of minor-unit digits, 2 for cents';
+
+INSERT INTO "currencies" ("code", "exponent", "symbol", "enabled") VALUES
+  ('USD', 2, '$', true),
+  ('EUR', 2, '€', true),
+  ('CAD', 2, 'CA$', true),
+  ('JPY', 0, '¥', false);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
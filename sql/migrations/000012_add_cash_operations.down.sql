@@ -0,0 +1,13 @@
+DELETE FROM "entries" WHERE "kind" <> 'transfer';
+
+DELETE FROM "accounts" WHERE "owner" = 'system';
+
+DELETE FROM "users" WHERE "username" = 'system';
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "description";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "reference";
+
+ALTER TABLE IF EXISTS "entries" DROP CONSTRAINT IF EXISTS "entry_kind_supported";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "kind";
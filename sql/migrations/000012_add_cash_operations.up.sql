@@ -0,0 +1,20 @@
+ALTER TABLE "entries" ADD COLUMN "kind" varchar NOT NULL DEFAULT 'transfer';
+
+ALTER TABLE "entries" ADD CONSTRAINT "entry_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal'));
+
+ALTER TABLE "entries" ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "entries" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "entries"."kind" IS 'operation that wrote the entry';
+
+COMMENT ON COLUMN "entries"."reference" IS 'external reference of a deposit or withdrawal';
+
+-- The system user owns one cash account per currency. Deposits and
+-- withdrawals are booked against it so that every entry has a counterpart.
+-- Its empty password hash can never match, so it cannot log in.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('system', '', 'GoBank System', 'system@gobank.invalid');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+SELECT 'system', 0, "code" FROM "currencies";
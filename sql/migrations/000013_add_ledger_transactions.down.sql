@@ -0,0 +1,19 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "ledger_transaction_id";
+
+DROP TABLE IF EXISTS "ledger_transactions";
+
+-- Drop the exchange legs booked on the system cash accounts and take them
+-- back out of their balances.
+UPDATE "accounts" c SET "balance" = c."balance" - fx."amount"
+FROM (
+  SELECT e."account_id", SUM(e."amount") AS "amount"
+  FROM "entries" e
+  JOIN "accounts" a ON a."id" = e."account_id"
+  WHERE a."owner" = 'system' AND e."kind" = 'transfer'
+  GROUP BY e."account_id"
+) fx
+WHERE c."id" = fx."account_id";
+
+DELETE FROM "entries" e
+USING "accounts" a
+WHERE a."id" = e."account_id" AND a."owner" = 'system' AND e."kind" = 'transfer';
@@ -0,0 +1,76 @@
+CREATE TABLE "ledger_transactions" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transaction_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal'));
+
+CREATE INDEX ON "ledger_transactions" ("transfer_id");
+
+COMMENT ON TABLE "ledger_transactions" IS 'journal grouping the entries of one money movement; its entries net to zero per currency';
+
+ALTER TABLE "ledger_transactions" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- Cross-currency transfers used to write a debit and a credit in different
+-- currencies. Book the missing legs on the system cash accounts so that
+-- their journals balance.
+INSERT INTO "entries" ("account_id", "amount", "transfer_id", "kind", "created_at")
+SELECT c."id", t."amount", t."id", 'transfer', t."created_at"
+FROM "transfers" t
+JOIN "accounts" c ON c."owner" = 'system' AND c."currency" = t."from_currency"
+WHERE t."from_currency" <> t."to_currency";
+
+INSERT INTO "entries" ("account_id", "amount", "transfer_id", "kind", "created_at")
+SELECT c."id", -t."to_amount", t."id", 'transfer', t."created_at"
+FROM "transfers" t
+JOIN "accounts" c ON c."owner" = 'system' AND c."currency" = t."to_currency"
+WHERE t."from_currency" <> t."to_currency";
+
+UPDATE "accounts" c SET "balance" = c."balance" + fx."amount"
+FROM (
+  SELECT "currency", SUM("amount") AS "amount" FROM (
+    SELECT "from_currency" AS "currency", "amount" FROM "transfers" WHERE "from_currency" <> "to_currency"
+    UNION ALL
+    SELECT "to_currency", -"to_amount" FROM "transfers" WHERE "from_currency" <> "to_currency"
+  ) legs
+  GROUP BY "currency"
+) fx
+WHERE c."owner" = 'system' AND c."currency" = fx."currency";
+
+ALTER TABLE "entries" ADD COLUMN "ledger_transaction_id" bigint;
+
+INSERT INTO "ledger_transactions" ("kind", "transfer_id", "created_at")
+SELECT 'transfer', "id", "created_at" FROM "transfers";
+
+UPDATE "entries" e SET "ledger_transaction_id" = lt."id"
+FROM "ledger_transactions" lt
+WHERE lt."transfer_id" = e."transfer_id";
+
+-- Entries written outside transfers were created in pairs by one
+-- transaction, so they share their kind, reference and timestamp.
+WITH "groups" AS (
+  SELECT nextval('ledger_transactions_id_seq') AS "id", g.*
+  FROM (
+    SELECT DISTINCT "kind", "reference", "description", "created_at"
+    FROM "entries"
+    WHERE "ledger_transaction_id" IS NULL
+  ) g
+), "journals" AS (
+  INSERT INTO "ledger_transactions" ("id", "kind", "created_at")
+  SELECT "id", "kind", "created_at" FROM "groups"
+)
+UPDATE "entries" e SET "ledger_transaction_id" = g."id"
+FROM "groups" g
+WHERE e."ledger_transaction_id" IS NULL
+  AND e."kind" = g."kind"
+  AND e."reference" = g."reference"
+  AND e."description" = g."description"
+  AND e."created_at" = g."created_at";
+
+ALTER TABLE "entries" ALTER COLUMN "ledger_transaction_id" SET NOT NULL;
+
+CREATE INDEX ON "entries" ("ledger_transaction_id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("ledger_transaction_id") REFERENCES "ledger_transactions" ("id");
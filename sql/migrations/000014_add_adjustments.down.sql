@@ -0,0 +1,11 @@
+DELETE FROM "entries" WHERE "kind" = 'adjustment';
+
+DELETE FROM "ledger_transactions" WHERE "kind" = 'adjustment';
+
+ALTER TABLE IF EXISTS "ledger_transactions" DROP CONSTRAINT IF EXISTS "ledger_transaction_kind_supported";
+
+ALTER TABLE IF EXISTS "ledger_transactions" ADD CONSTRAINT "ledger_transaction_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal'));
+
+ALTER TABLE IF EXISTS "entries" DROP CONSTRAINT IF EXISTS "entry_kind_supported";
+
+ALTER TABLE IF EXISTS "entries" ADD CONSTRAINT "entry_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal'));
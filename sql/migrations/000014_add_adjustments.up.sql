@@ -0,0 +1,7 @@
+ALTER TABLE "entries" DROP CONSTRAINT "entry_kind_supported";
+
+ALTER TABLE "entries" ADD CONSTRAINT "entry_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal', 'adjustment'));
+
+ALTER TABLE "ledger_transactions" DROP CONSTRAINT "ledger_transaction_kind_supported";
+
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transaction_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal', 'adjustment'));
@@ -0,0 +1,17 @@
+DELETE FROM "entries" WHERE "kind" = 'reversal';
+
+DELETE FROM "ledger_transactions" WHERE "kind" = 'reversal';
+
+DELETE FROM "transfers" WHERE "reversal_of" IS NOT NULL;
+
+ALTER TABLE IF EXISTS "ledger_transactions" DROP CONSTRAINT IF EXISTS "ledger_transaction_kind_supported";
+
+ALTER TABLE IF EXISTS "ledger_transactions" ADD CONSTRAINT "ledger_transaction_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal', 'adjustment'));
+
+ALTER TABLE IF EXISTS "entries" DROP CONSTRAINT IF EXISTS "entry_kind_supported";
+
+ALTER TABLE IF EXISTS "entries" ADD CONSTRAINT "entry_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal', 'adjustment'));
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "reversed_amount";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "reversal_of";
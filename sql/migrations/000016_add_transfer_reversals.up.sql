@@ -0,0 +1,21 @@
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "reversed_amount_within_transfer" CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "to_amount");
+
+CREATE INDEX ON "transfers" ("reversal_of");
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'transfer this one refunds, if it is a reversal';
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'part of to_amount refunded by reversals so far';
+
+ALTER TABLE "entries" DROP CONSTRAINT "entry_kind_supported";
+
+ALTER TABLE "entries" ADD CONSTRAINT "entry_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal', 'adjustment', 'reversal'));
+
+ALTER TABLE "ledger_transactions" DROP CONSTRAINT "ledger_transaction_kind_supported";
+
+ALTER TABLE "ledger_transactions" ADD CONSTRAINT "ledger_transaction_kind_supported" CHECK ("kind" IN ('transfer', 'deposit', 'withdrawal', 'adjustment', 'reversal'));
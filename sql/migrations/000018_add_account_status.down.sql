@@ -0,0 +1,11 @@
+ALTER TABLE IF EXISTS "accounts" ADD COLUMN IF NOT EXISTS "is_frozen" boolean NOT NULL DEFAULT false;
+
+-- Closed accounts have no equivalent before this migration and are frozen
+-- so that they stay out of use.
+UPDATE "accounts" SET "is_frozen" = true WHERE "status" IN ('frozen', 'closed');
+
+COMMENT ON COLUMN "accounts"."is_frozen" IS 'frozen accounts can neither send nor receive transfers';
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "closed_at";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status";
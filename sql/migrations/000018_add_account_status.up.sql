@@ -0,0 +1,11 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts" ADD COLUMN "closed_at" timestamptz;
+
+UPDATE "accounts" SET "status" = 'frozen' WHERE "is_frozen";
+
+ALTER TABLE "accounts" DROP COLUMN "is_frozen";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "account_status_supported" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+COMMENT ON COLUMN "accounts"."status" IS 'only active accounts can send or receive money; closed ones are kept for their history';
@@ -0,0 +1,37 @@
+ALTER TABLE "users" ADD COLUMN "is_email_verified" bool NOT NULL DEFAULT false;
+
+-- Users created before emails were verified keep moving money.
+UPDATE "users" SET "is_email_verified" = true;
+
+CREATE TABLE "verify_emails" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "code_hash" varchar UNIQUE NOT NULL,
+  "used_at" timestamptz,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "password_resets" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "code_hash" varchar UNIQUE NOT NULL,
+  "used_at" timestamptz,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "verify_emails" ("username");
+
+CREATE INDEX ON "password_resets" ("username");
+
+COMMENT ON COLUMN "verify_emails"."email" IS 'address the code was sent to, verified only while it is still the user''s email';
+
+COMMENT ON COLUMN "verify_emails"."code_hash" IS 'sha256 of the code sent by email';
+
+COMMENT ON COLUMN "password_resets"."code_hash" IS 'sha256 of the code sent by email';
+
+ALTER TABLE "verify_emails" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "password_resets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,16 @@
+CREATE TABLE "login_attempts" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "outcome" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "login_attempts" ADD CONSTRAINT "login_outcome_supported" CHECK ("outcome" IN ('success', 'invalid_credentials', 'throttled'));
+
+CREATE INDEX ON "login_attempts" ("username", "created_at");
+
+CREATE INDEX ON "login_attempts" ("client_ip", "created_at");
+
+COMMENT ON COLUMN "login_attempts"."username" IS 'as typed by the client, whether or not such a user exists';
@@ -0,0 +1,11 @@
+DELETE FROM "login_attempts" WHERE "outcome" = 'mfa_required';
+
+ALTER TABLE "login_attempts" DROP CONSTRAINT "login_outcome_supported";
+
+ALTER TABLE "login_attempts" ADD CONSTRAINT "login_outcome_supported" CHECK ("outcome" IN ('success', 'invalid_credentials', 'throttled'));
+
+DROP TABLE IF EXISTS "mfa_challenges";
+
+DROP TABLE IF EXISTS "recovery_codes";
+
+DROP TABLE IF EXISTS "totp_secrets";
@@ -0,0 +1,47 @@
+CREATE TABLE "totp_secrets" (
+  "username" varchar PRIMARY KEY,
+  "secret" varchar NOT NULL,
+  "confirmed_at" timestamptz,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "mfa_challenges" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "used_at" timestamptz,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "recovery_codes" ("username");
+
+CREATE INDEX ON "mfa_challenges" ("username");
+
+COMMENT ON COLUMN "totp_secrets"."confirmed_at" IS 'two-factor authentication is enabled once the secret is confirmed';
+
+COMMENT ON COLUMN "totp_secrets"."last_used_step" IS 'time step of the last accepted code, so that no code is accepted twice';
+
+COMMENT ON COLUMN "recovery_codes"."code_hash" IS 'bcrypt hash of the recovery code';
+
+COMMENT ON COLUMN "mfa_challenges"."token_hash" IS 'sha256 of the challenge token returned by the login';
+
+ALTER TABLE "totp_secrets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "mfa_challenges" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "login_attempts" DROP CONSTRAINT "login_outcome_supported";
+
+ALTER TABLE "login_attempts" ADD CONSTRAINT "login_outcome_supported" CHECK ("outcome" IN ('success', 'invalid_credentials', 'throttled', 'mfa_required'));
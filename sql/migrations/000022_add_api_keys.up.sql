@@ -0,0 +1,37 @@
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "prefix" varchar UNIQUE NOT NULL,
+  "secret_hash" varchar NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "api_key_scoped" CHECK (cardinality("scopes") > 0)
+);
+
+CREATE TABLE "api_key_usages" (
+  "id" bigserial PRIMARY KEY,
+  "api_key_id" bigint NOT NULL,
+  "method" varchar NOT NULL,
+  "path" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "api_keys" ("username");
+
+CREATE INDEX ON "api_key_usages" ("api_key_id", "created_at");
+
+COMMENT ON COLUMN "api_keys"."prefix" IS 'public part of the key it is looked up by';
+
+COMMENT ON COLUMN "api_keys"."secret_hash" IS 'sha256 of the secret part of the key';
+
+COMMENT ON COLUMN "api_keys"."expires_at" IS 'keys without an expiry are valid until revoked';
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "api_key_usages" ADD FOREIGN KEY ("api_key_id") REFERENCES "api_keys" ("id");
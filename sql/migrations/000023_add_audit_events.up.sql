@@ -0,0 +1,43 @@
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "resource_type" varchar NOT NULL,
+  "resource_id" varchar NOT NULL,
+  "request_id" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "before" json NOT NULL,
+  "after" json NOT NULL,
+  "prev_hash" varchar NOT NULL,
+  "hash" varchar UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "audit_events" ("actor", "created_at");
+
+CREATE INDEX ON "audit_events" ("resource_type", "resource_id");
+
+CREATE INDEX ON "audit_events" ("created_at", "id");
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'user the operation was made by, or system for background jobs';
+
+COMMENT ON COLUMN "audit_events"."before" IS 'json rather than jsonb, so that the text is kept exactly as hashed';
+
+COMMENT ON COLUMN "audit_events"."prev_hash" IS 'hash of the previous event, or 64 zeros for the first one';
+
+COMMENT ON COLUMN "audit_events"."hash" IS 'sha256 of prev_hash and the fields of this event';
+
+CREATE FUNCTION "reject_audit_event_change"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_append_only"
+BEFORE UPDATE OR DELETE ON "audit_events"
+FOR EACH ROW EXECUTE FUNCTION "reject_audit_event_change"();
+
+CREATE TRIGGER "audit_events_no_truncate"
+BEFORE TRUNCATE ON "audit_events"
+FOR EACH STATEMENT EXECUTE FUNCTION "reject_audit_event_change"();
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS "currencies_cash_account" ON "currencies";
+
+DROP FUNCTION IF EXISTS "create_cash_account";
@@ -0,0 +1,18 @@
+-- Every currency needs a cash account for deposits, withdrawals and
+-- exchanges to be booked against, including those added after 000012.
+INSERT INTO "accounts" ("owner", "balance", "currency")
+SELECT 'system', 0, "code" FROM "currencies"
+ON CONFLICT ("owner", "currency") DO NOTHING;
+
+CREATE FUNCTION "create_cash_account"() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO "accounts" ("owner", "balance", "currency")
+  VALUES ('system', 0, NEW."code")
+  ON CONFLICT ("owner", "currency") DO NOTHING;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "currencies_cash_account"
+AFTER INSERT ON "currencies"
+FOR EACH ROW EXECUTE FUNCTION "create_cash_account"();
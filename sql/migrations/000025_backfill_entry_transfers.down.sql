@@ -0,0 +1,2 @@
+-- The backfilled transfer_ids cannot be told apart from those written by
+-- transfers, and are correct either way, so they are kept.
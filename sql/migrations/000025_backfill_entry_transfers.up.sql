@@ -0,0 +1,24 @@
+-- Entries written before 000005 have no transfer_id. A transfer and its two
+-- entries were inserted in one transaction, so they share created_at: each
+-- entry is matched to the transfer of its account, amount and time, when
+-- there is exactly one. Entries left unmatched keep no transfer_id, and
+-- statements show them without a counterparty.
+WITH "matches" AS (
+  SELECT
+    e."id" AS "entry_id",
+    t."id" AS "transfer_id",
+    count(*) OVER (PARTITION BY e."id") AS "candidates"
+  FROM "entries" e
+  JOIN "transfers" t ON t."created_at" = e."created_at"
+    AND (
+      (t."from_account_id" = e."account_id" AND e."amount" = -t."amount")
+      OR (t."to_account_id" = e."account_id" AND e."amount" = t."amount")
+    )
+  WHERE e."transfer_id" IS NULL
+    AND e."kind" = 'transfer'
+)
+UPDATE "entries"
+SET "transfer_id" = "matches"."transfer_id"
+FROM "matches"
+WHERE "entries"."id" = "matches"."entry_id"
+  AND "matches"."candidates" = 1;
@@ -22,5 +22,16 @@ UPDATE accounts SET balance = $2 WHERE id = $1 RETURNING *;
 -- name: AddAccountBalance :one
 UPDATE accounts SET balance = balance + sqlc.arg(amout) WHERE id = sqlc.arg(id) RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts WHERE id = $1;
\ No newline at end of file
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts SET overdraft_limit = sqlc.arg(overdraft_limit) WHERE id = sqlc.arg(id) RETURNING *;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET
+    status = sqlc.arg(status),
+    closed_at = CASE WHEN sqlc.arg(status) = 'closed' THEN now() END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetCashAccount :one
+SELECT * FROM accounts WHERE owner = 'system' AND currency = $1 LIMIT 1;
@@ -0,0 +1,55 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    username,
+    name,
+    prefix,
+    secret_hash,
+    scopes,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAPIKey :one
+SELECT * FROM api_keys
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT * FROM api_keys
+WHERE prefix = $1 LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+WHERE username = $1
+ORDER BY id;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now())
+WHERE id = $1
+RETURNING *;
+
+-- name: RevokeUserAPIKeys :exec
+UPDATE api_keys SET revoked_at = now()
+WHERE username = $1 AND revoked_at IS NULL;
+
+-- name: RecordAPIKeyUsage :exec
+WITH used AS (
+    UPDATE api_keys SET last_used_at = now()
+    WHERE id = sqlc.arg(api_key_id)
+)
+INSERT INTO api_key_usages (
+    api_key_id,
+    method,
+    path,
+    client_ip,
+    user_agent
+) VALUES (
+    sqlc.arg(api_key_id), sqlc.arg(method), sqlc.arg(path), sqlc.arg(client_ip), sqlc.arg(user_agent)
+);
+
+-- name: ListAPIKeyUsages :many
+SELECT * FROM api_key_usages
+WHERE api_key_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,14 @@
+-- name: ListCurrencies :many
+SELECT * FROM currencies ORDER BY code;
+
+-- name: UpsertCurrency :one
+INSERT INTO currencies (
+  code,
+  exponent,
+  symbol,
+  enabled
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (code) DO UPDATE
+SET symbol = EXCLUDED.symbol, enabled = EXCLUDED.enabled
+RETURNING *;
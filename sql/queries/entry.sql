@@ -1,8 +1,55 @@
 -- name: CreateEntry :one
-INSERT INTO entries (account_id, amount) VALUES ($1, $2) RETURNING *;
+INSERT INTO entries (
+    account_id,
+    amount,
+    transfer_id,
+    kind,
+    reference,
+    description,
+    ledger_transaction_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
 
 -- name: GetEntry :one
 SELECT * FROM entries WHERE id = $1 LIMIT 1;
 
 -- name: ListEntries :many
-SELECT * FROM entries WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3;
\ No newline at end of file
+SELECT * FROM entries WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3;
+
+-- name: ListAccountStatement :many
+SELECT
+    s.id,
+    s.account_id,
+    s.amount,
+    s.kind,
+    s.reference,
+    s.description,
+    s.transfer_id,
+    s.counterparty_account_id,
+    s.running_balance,
+    s.created_at
+FROM (
+    SELECT
+        e.id,
+        e.account_id,
+        e.amount,
+        e.kind,
+        e.reference,
+        e.description,
+        e.transfer_id,
+        e.created_at,
+        (CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END)::bigint AS counterparty_account_id,
+        (SUM(e.amount) OVER (ORDER BY e.id ROWS UNBOUNDED PRECEDING))::bigint AS running_balance
+    FROM entries e
+    LEFT JOIN transfers t ON t.id = e.transfer_id
+    WHERE e.account_id = sqlc.arg(account_id)
+) s
+WHERE (sqlc.narg(from_time)::timestamptz IS NULL OR s.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR s.created_at < sqlc.narg(to_time))
+  AND s.id > sqlc.arg(after_id)
+ORDER BY s.id
+LIMIT sqlc.arg(page_size);
+
+-- name: GetAccountEntriesTotal :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries WHERE account_id = $1;
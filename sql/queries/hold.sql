@@ -0,0 +1,35 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    account_id,
+    to_account_id,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: ListAccountHolds :many
+SELECT * FROM holds
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: GetAccountHeldAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS held FROM holds
+WHERE account_id = $1 AND status = 'active' AND expires_at > now();
+
+-- name: SettleHold :one
+UPDATE holds
+SET
+    status = sqlc.arg(status),
+    transfer_id = sqlc.narg(transfer_id),
+    settled_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -0,0 +1,31 @@
+-- name: CreateLedgerTransaction :one
+INSERT INTO ledger_transactions (kind, transfer_id) VALUES ($1, $2) RETURNING *;
+
+-- name: GetLedgerTransaction :one
+SELECT * FROM ledger_transactions WHERE id = $1 LIMIT 1;
+
+-- name: ListLedgerTransactionEntries :many
+SELECT * FROM entries WHERE ledger_transaction_id = $1 ORDER BY id;
+
+-- name: ListAccountBalanceMismatches :many
+SELECT
+    a.id AS account_id,
+    a.currency,
+    a.balance,
+    COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListUnbalancedLedgerTransactions :many
+SELECT
+    e.ledger_transaction_id,
+    a.currency,
+    SUM(e.amount)::bigint AS total
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+GROUP BY e.ledger_transaction_id, a.currency
+HAVING SUM(e.amount) <> 0
+ORDER BY e.ledger_transaction_id, a.currency;
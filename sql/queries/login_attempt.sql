@@ -0,0 +1,34 @@
+-- name: CreateLoginAttempt :one
+INSERT INTO login_attempts (
+    username,
+    client_ip,
+    user_agent,
+    outcome
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetUsernameLoginFailures :one
+SELECT
+    count(*)::bigint AS failures,
+    COALESCE(min(created_at), '0001-01-01 00:00:00Z')::timestamptz AS first_failed_at,
+    COALESCE(max(created_at), '0001-01-01 00:00:00Z')::timestamptz AS last_failed_at
+FROM login_attempts
+WHERE username = sqlc.arg(username)
+  AND outcome = 'invalid_credentials'
+  AND created_at > sqlc.arg(since)
+  AND created_at > (
+      SELECT COALESCE(max(created_at), '0001-01-01 00:00:00Z')
+      FROM login_attempts
+      WHERE username = sqlc.arg(username) AND outcome = 'success'
+  );
+
+-- name: GetClientIPLoginFailures :one
+SELECT
+    count(*)::bigint AS failures,
+    COALESCE(min(created_at), '0001-01-01 00:00:00Z')::timestamptz AS first_failed_at,
+    COALESCE(max(created_at), '0001-01-01 00:00:00Z')::timestamptz AS last_failed_at
+FROM login_attempts
+WHERE client_ip = sqlc.arg(client_ip)
+  AND outcome = 'invalid_credentials'
+  AND created_at > sqlc.arg(since);
@@ -0,0 +1,20 @@
+-- name: CreateMFAChallenge :one
+INSERT INTO mfa_challenges (
+    username,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: ClaimMFAChallengeAttempt :one
+UPDATE mfa_challenges SET attempts = attempts + 1
+WHERE token_hash = sqlc.arg(token_hash)
+  AND used_at IS NULL
+  AND expires_at > now()
+  AND attempts < sqlc.arg(max_attempts)
+RETURNING *;
+
+-- name: UseMFAChallenge :execrows
+UPDATE mfa_challenges SET used_at = now()
+WHERE id = $1 AND used_at IS NULL;
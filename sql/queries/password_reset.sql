@@ -0,0 +1,15 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+    username,
+    code_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: UsePasswordReset :one
+UPDATE password_resets SET used_at = now()
+WHERE code_hash = sqlc.arg(code_hash)
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
@@ -0,0 +1,73 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    owner,
+    from_account_id,
+    to_account_id,
+    amount,
+    currency,
+    frequency,
+    start_at,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $7
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers WHERE owner = $1 ORDER BY id LIMIT $2 OFFSET $3;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+    amount = COALESCE(sqlc.narg(amount), amount),
+    status = COALESCE(sqlc.narg(status), status),
+    next_run_at = COALESCE(sqlc.narg(next_run_at), next_run_at),
+    attempts = CASE WHEN sqlc.narg(status) = 'active' THEN 0 ELSE attempts END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteScheduledTransfer :exec
+DELETE FROM scheduled_transfers WHERE id = $1;
+
+-- name: ClaimDueScheduledTransfers :many
+UPDATE scheduled_transfers
+SET locked_until = sqlc.arg(locked_until)
+WHERE id IN (
+    SELECT id FROM scheduled_transfers
+    WHERE status = 'active'
+      AND next_run_at <= sqlc.arg(now)
+      AND (locked_until IS NULL OR locked_until < sqlc.arg(now))
+    ORDER BY next_run_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RecordScheduledTransferSuccess :execrows
+UPDATE scheduled_transfers
+SET
+    runs = runs + 1,
+    attempts = 0,
+    last_error = '',
+    last_run_at = now(),
+    last_transfer_id = sqlc.arg(last_transfer_id),
+    next_run_at = sqlc.arg(next_run_at),
+    status = sqlc.arg(status),
+    locked_until = NULL
+WHERE id = sqlc.arg(id) AND runs = sqlc.arg(runs);
+
+-- name: RecordScheduledTransferFailure :execrows
+UPDATE scheduled_transfers
+SET
+    attempts = attempts + 1,
+    last_error = sqlc.arg(last_error),
+    last_run_at = now(),
+    next_run_at = sqlc.arg(next_run_at),
+    status = sqlc.arg(status),
+    locked_until = NULL
+WHERE id = sqlc.arg(id) AND runs = sqlc.arg(runs);
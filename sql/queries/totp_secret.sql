@@ -0,0 +1,30 @@
+-- name: UpsertTOTPSecret :one
+INSERT INTO totp_secrets (
+    username,
+    secret
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (username) DO UPDATE
+SET secret = EXCLUDED.secret, last_used_step = 0, created_at = now()
+WHERE totp_secrets.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetTOTPSecret :one
+SELECT * FROM totp_secrets
+WHERE username = $1 LIMIT 1;
+
+-- name: ConfirmTOTPSecret :one
+UPDATE totp_secrets
+SET confirmed_at = now(), last_used_step = sqlc.arg(step)
+WHERE username = sqlc.arg(username)
+  AND confirmed_at IS NULL
+  AND last_used_step < sqlc.arg(step)
+RETURNING *;
+
+-- name: UseTOTPStep :execrows
+UPDATE totp_secrets
+SET last_used_step = sqlc.arg(step)
+WHERE username = sqlc.arg(username)
+  AND confirmed_at IS NOT NULL
+  AND last_used_step < sqlc.arg(step);
@@ -1,8 +1,64 @@
 -- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount) VALUES ($1, $2, $3) RETURNING *;
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    from_currency,
+    to_amount,
+    to_currency,
+    exchange_rate,
+    rate_updated_at,
+    reversal_of
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: ListTransfers :many
-SELECT * FROM transfers WHERE from_account_id = $1 OR to_account_id = $2 ORDER BY id LIMIT $3 OFFSET $4;
\ No newline at end of file
+SELECT * FROM transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND (sqlc.narg(direction)::varchar IS NULL
+    OR (sqlc.narg(direction) = 'out' AND from_account_id = sqlc.arg(account_id))
+    OR (sqlc.narg(direction) = 'in' AND to_account_id = sqlc.arg(account_id)))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL
+    OR (from_account_id = sqlc.arg(account_id) AND to_account_id = sqlc.narg(counterparty_id))
+    OR (to_account_id = sqlc.arg(account_id) AND from_account_id = sqlc.narg(counterparty_id)))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListUserTransfers :many
+SELECT t.* FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (fa.owner = sqlc.arg(owner) OR ta.owner = sqlc.arg(owner))
+  AND (sqlc.narg(direction)::varchar IS NULL
+    OR (sqlc.narg(direction) = 'out' AND fa.owner = sqlc.arg(owner))
+    OR (sqlc.narg(direction) = 'in' AND ta.owner = sqlc.arg(owner)))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL
+    OR (fa.owner = sqlc.arg(owner) AND t.to_account_id = sqlc.narg(counterparty_id))
+    OR (ta.owner = sqlc.arg(owner) AND t.from_account_id = sqlc.narg(counterparty_id)))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (t.created_at, t.id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg(page_size);
@@ -11,3 +11,40 @@ INSERT INTO users (
 -- name: GetUser :one
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
+-- name: IsUserEmailVerified :one
+SELECT is_email_verified FROM users
+WHERE username = $1;
+
+-- name: MarkUserEmailVerified :one
+UPDATE users SET is_email_verified = true
+WHERE username = sqlc.arg(username) AND email = sqlc.arg(email)
+RETURNING *;
+
+-- name: RevokeUserTokens :exec
+UPDATE users SET tokens_revoked_at = sqlc.arg(revoked_at)
+WHERE username = sqlc.arg(username);
+
+-- name: UpdateUserRole :one
+UPDATE users SET role = sqlc.arg(role) WHERE username = sqlc.arg(username) RETURNING *;
+
+-- name: UpdateUser :one
+UPDATE users
+SET
+    full_name = COALESCE(sqlc.narg(full_name), full_name),
+    email = COALESCE(sqlc.narg(email), email),
+    is_email_verified = is_email_verified AND COALESCE(sqlc.narg(email), email) = email
+WHERE username = sqlc.arg(username)
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET
+    hashed_password = sqlc.arg(hashed_password),
+    password_changed_at = sqlc.arg(password_changed_at)
+WHERE username = sqlc.arg(username)
+RETURNING *;
@@ -0,0 +1,16 @@
+-- name: CreateVerifyEmail :one
+INSERT INTO verify_emails (
+    username,
+    email,
+    code_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UseVerifyEmail :one
+UPDATE verify_emails SET used_at = now()
+WHERE code_hash = sqlc.arg(code_hash)
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
@@ -0,0 +1,36 @@
+package token
+
+import (
+	"errors"
+	"time"
+)
+
+// DualTokenCreator creates tokens with one creator and verifies them with
+// both, so that switching token formats or secrets does not log out the
+// users holding tokens of the previous one. It is meant for the migration
+// window only: once the last previous token has expired, the previous
+// creator should be dropped.
+type DualTokenCreator struct {
+	current  TokenCreator
+	previous TokenCreator
+}
+
+func NewDualTokenCreator(current TokenCreator, previous TokenCreator) TokenCreator {
+	return &DualTokenCreator{
+		current:  current,
+		previous: previous,
+	}
+}
+
+func (creator *DualTokenCreator) CreateToken(username string, role string, duration time.Duration, claims Claims) (string, *Payload, error) {
+	return creator.current.CreateToken(username, role, duration, claims)
+}
+
+func (creator *DualTokenCreator) VerifyToken(token string) (*Payload, error) {
+	payload, err := creator.current.VerifyToken(token)
+	if err == nil || errors.Is(err, ErrExpiredToken) {
+		return payload, err
+	}
+
+	return creator.previous.VerifyToken(token)
+}
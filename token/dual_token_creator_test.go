@@ -0,0 +1,52 @@
+package token
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestDualTokenCreator(t *testing.T) {
+	previous, err := NewPasetoTokenCreator(utils.RandomString(32), Options{})
+	require.NoError(t, err)
+
+	current, err := NewJWTEdDSATokenCreator(newTestKeyring(t, "key-1"), Options{})
+	require.NoError(t, err)
+
+	tokenCreator := NewDualTokenCreator(current, previous)
+
+	// New tokens are created in the current format.
+	token, created, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+	require.NoError(t, err)
+	require.False(t, strings.HasPrefix(token, "v2.local."))
+
+	payload, err := tokenCreator.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, payload.ID)
+
+	// Tokens issued before the migration are still accepted.
+	previousToken, previousCreated, err := previous.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+	require.NoError(t, err)
+
+	payload, err = tokenCreator.VerifyToken(previousToken)
+	require.NoError(t, err)
+	require.Equal(t, previousCreated.ID, payload.ID)
+
+	// Expired tokens of either format are reported as expired.
+	expiredToken, _, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, -time.Minute, Claims{})
+	require.NoError(t, err)
+	_, err = tokenCreator.VerifyToken(expiredToken)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+
+	expiredToken, _, err = previous.CreateToken(utils.RandomOwner(), utils.DepositorRole, -time.Minute, Claims{})
+	require.NoError(t, err)
+	_, err = tokenCreator.VerifyToken(expiredToken)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+
+	payload, err = tokenCreator.VerifyToken("invalid-token")
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
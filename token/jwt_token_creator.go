@@ -3,52 +3,176 @@ package token
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 const minSecretKeySize = 32
 
+// JWTTokenCreator creates JSON Web Tokens, either signed with a shared
+// HS256 secret or with the EdDSA keys of a keyring.
 type JWTTokenCreator struct {
-    secretKey string
+	method     jwt.SigningMethod
+	signingKey interface{}
+	// keyID names the signing key in the header of new tokens. It is empty
+	// for HS256, which has a single key.
+	keyID   string
+	keyFunc jwt.Keyfunc
+	options Options
 }
 
-func NewJWTTokenCreator(secretKey string) (TokenCreator, error) {
-    if len(secretKey) < minSecretKeySize {
-        return nil, fmt.Errorf("secret key must be at least %d characters", minSecretKeySize)
-    }
-    return &JWTTokenCreator{secretKey}, nil
+// jwtClaims are the registered claims of RFC 7519 that a Payload maps to,
+// along with the role of the user, the type of token, the space-separated
+// scopes of RFC 8693 and the session id.
+type jwtClaims struct {
+	Role      string `json:"role"`
+	Type      string `json:"typ"`
+	Scope     string `json:"scope,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	// IssuedAtNano is the issue time in nanoseconds since the epoch, which
+	// iat rounds down to the second. Revocations are compared with it, so
+	// that a token issued right after one in the same second is kept.
+	IssuedAtNano int64 `json:"iat_ns,omitempty"`
+	jwt.RegisteredClaims
 }
 
-func (jwtT *JWTTokenCreator) CreateToken(username string, duration time.Duration) (string, error) {
-    payload, err := NewPayload(username, duration)
-    if err != nil {
-        return "", err
-    }
-    jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-    return jwtToken.SignedString([]byte(jwtT.secretKey))
+// NewJWTTokenCreator creates HS256 tokens. Every service verifying them
+// needs secretKey, which also lets it create tokens.
+func NewJWTTokenCreator(secretKey string, options Options) (TokenCreator, error) {
+	if len(secretKey) < minSecretKeySize {
+		return nil, fmt.Errorf("secret key must be at least %d characters", minSecretKeySize)
+	}
+
+	return &JWTTokenCreator{
+		method:     jwt.SigningMethodHS256,
+		signingKey: []byte(secretKey),
+		keyFunc: func(*jwt.Token) (interface{}, error) {
+			return []byte(secretKey), nil
+		},
+		options: options,
+	}, nil
+}
+
+// NewJWTEdDSATokenCreator creates EdDSA tokens signed with the signing key
+// of keyring and naming it in their kid header.
+func NewJWTEdDSATokenCreator(keyring *Keyring, options Options) (TokenCreator, error) {
+	if keyring == nil {
+		return nil, errors.New("keyring is required")
+	}
+
+	return &JWTTokenCreator{
+		method:     jwt.SigningMethodEdDSA,
+		signingKey: keyring.signingKey,
+		keyID:      keyring.signingKeyID,
+		keyFunc: func(token *jwt.Token) (interface{}, error) {
+			keyID, _ := token.Header["kid"].(string)
+			key, ok := keyring.verificationKey(keyID)
+			if !ok {
+				return nil, ErrInvalidToken
+			}
+			return key, nil
+		},
+		options: options,
+	}, nil
 }
 
+func (creator *JWTTokenCreator) CreateToken(username string, role string, duration time.Duration, tokenClaims Claims) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, duration, tokenClaims, creator.options)
+	if err != nil {
+		return "", nil, err
+	}
 
-func (jwtT *JWTTokenCreator) VerifyToken(token string) (*Payload, error){
-    keyFunc := func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, ErrInvalidToken
-        }
-        return []byte(jwtT.secretKey), nil
-    }
-    jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
-    if err != nil {
-        verr, ok := err.(*jwt.ValidationError)
-        if ok && errors.Is(verr.Inner, ErrExpiredToken) {
-            return nil, ErrExpiredToken
-        }
-        return nil, ErrInvalidToken
-    }
-    payload, ok := jwtToken.Claims.(*Payload)
-    if !ok {
-        return nil, ErrInvalidToken
-    }
-    return payload, nil
-}
\ No newline at end of file
+	claims := jwtClaims{
+		Role:         payload.Role,
+		Type:         payload.Type,
+		Scope:        strings.Join(payload.Scopes, " "),
+		IssuedAtNano: payload.IssuedAt.UnixNano(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        payload.ID.String(),
+			Subject:   payload.Username,
+			Issuer:    payload.Issuer,
+			IssuedAt:  jwt.NewNumericDate(payload.IssuedAt),
+			NotBefore: jwt.NewNumericDate(payload.NotBefore),
+			ExpiresAt: jwt.NewNumericDate(payload.ExpiredAt),
+		},
+	}
+	if payload.SessionID != uuid.Nil {
+		claims.SessionID = payload.SessionID.String()
+	}
+	if payload.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{payload.Audience}
+	}
+
+	jwtToken := jwt.NewWithClaims(creator.method, claims)
+	if creator.keyID != "" {
+		jwtToken.Header["kid"] = creator.keyID
+	}
+
+	token, err := jwtToken.SignedString(creator.signingKey)
+	return token, payload, err
+}
+
+func (creator *JWTTokenCreator) VerifyToken(token string) (*Payload, error) {
+	parserOptions := []jwt.ParserOption{
+		// Pinning the algorithm keeps tokens signed with "none", or with an
+		// HMAC keyed by a public key, from passing as ours.
+		jwt.WithValidMethods([]string{creator.method.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+	}
+	if creator.options.Issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(creator.options.Issuer))
+	}
+	if creator.options.Audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(creator.options.Audience))
+	}
+
+	claims := &jwtClaims{}
+	_, err := jwt.NewParser(parserOptions...).ParseWithClaims(token, claims, creator.keyFunc)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil || claims.Subject == "" || claims.IssuedAt == nil {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{
+		ID:        tokenID,
+		Username:  claims.Subject,
+		Role:      claims.Role,
+		Type:      claims.Type,
+		Issuer:    claims.Issuer,
+		Scopes:    strings.Fields(claims.Scope),
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiredAt: claims.ExpiresAt.Time,
+	}
+	if len(claims.Audience) > 0 {
+		payload.Audience = claims.Audience[0]
+	}
+	if claims.NotBefore != nil {
+		payload.NotBefore = claims.NotBefore.Time
+	}
+	if claims.SessionID != "" {
+		if payload.SessionID, err = uuid.Parse(claims.SessionID); err != nil {
+			return nil, ErrInvalidToken
+		}
+	}
+	if claims.IssuedAtNano != 0 {
+		issuedAt := time.Unix(0, claims.IssuedAtNano)
+		if !issuedAt.Truncate(time.Second).Equal(claims.IssuedAt.Time) {
+			return nil, ErrInvalidToken
+		}
+		payload.IssuedAt = issuedAt
+	}
+
+	return payload, nil
+}
@@ -4,62 +4,206 @@ import (
 	"testing"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/wenealves10/gobank/utils"
 )
 
 func TestJWTTokenCreator(t *testing.T) {
-    tokenCreator, err := NewJWTTokenCreator(utils.RandomString(32))
+    tokenCreator, err := NewJWTTokenCreator(utils.RandomString(32), Options{})
     require.NoError(t, err)
 
     username := utils.RandomOwner()
+    role := utils.DepositorRole
     duration := time.Minute
 
     issuedAt := time.Now()
     expiresAt := issuedAt.Add(duration)
 
-    token, err := tokenCreator.CreateToken(username, duration)
+    token, payload, err := tokenCreator.CreateToken(username, role, duration, Claims{})
     require.NoError(t, err)
     require.NotEmpty(t, token)
+    require.NotEmpty(t, payload)
 
-    payload, err := tokenCreator.VerifyToken(token)
+    payload, err = tokenCreator.VerifyToken(token)
     require.NoError(t, err)
     require.NotNil(t, payload)
 
     require.Equal(t, username, payload.Username)
+    require.Equal(t, role, payload.Role)
     require.NotZero(t, payload.ID)
     require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
     require.WithinDuration(t, expiresAt, payload.ExpiredAt, time.Second)
 }
 
+func TestJWTTokenCreatorIssuedAt(t *testing.T) {
+    secretKey := utils.RandomString(32)
+    tokenCreator, err := NewJWTTokenCreator(secretKey, Options{})
+    require.NoError(t, err)
+
+    token, created, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+    require.NoError(t, err)
+
+    // iat is in whole seconds, but the issue time is kept exactly.
+    claims := &jwtClaims{}
+    _, _, err = jwt.NewParser().ParseUnverified(token, claims)
+    require.NoError(t, err)
+    require.Equal(t, created.IssuedAt.Unix(), claims.IssuedAt.Unix())
+    require.Zero(t, claims.IssuedAt.Nanosecond())
+
+    payload, err := tokenCreator.VerifyToken(token)
+    require.NoError(t, err)
+    require.Equal(t, created.IssuedAt.UnixNano(), payload.IssuedAt.UnixNano())
+
+    // An exact issue time outside the second of iat is rejected.
+    tampered := newTestClaims(time.Hour)
+    tampered.IssuedAtNano = time.Now().Add(-time.Hour).UnixNano()
+
+    token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, tampered).SignedString([]byte(secretKey))
+    require.NoError(t, err)
+
+    payload, err = tokenCreator.VerifyToken(token)
+    require.EqualError(t, err, ErrInvalidToken.Error())
+    require.Nil(t, payload)
+}
+
 func TestExpiredJWTTokenCreator(t *testing.T) {
-    tokenCreator, err := NewJWTTokenCreator(utils.RandomString(32))
+    tokenCreator, err := NewJWTTokenCreator(utils.RandomString(32), Options{})
     require.NoError(t, err)
 
-    token, err := tokenCreator.CreateToken(utils.RandomOwner(), -time.Minute)
+    token, payload, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, -time.Minute, Claims{})
     require.NoError(t, err)
     require.NotEmpty(t, token)
+    require.NotEmpty(t, payload)
 
-    payload, err := tokenCreator.VerifyToken(token)
+    payload, err = tokenCreator.VerifyToken(token)
     require.Error(t, err)
     require.EqualError(t, err, ErrExpiredToken.Error())
     require.Nil(t, payload)
 }
 
 func TestInvalidJWTTokenCreatorAlgNone(t *testing.T){
-    payload, err := NewPayload(utils.RandomOwner(), time.Minute)
-    require.NoError(t, err)
-
-    jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
+    jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, newTestClaims(time.Minute))
     token, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
     require.NoError(t, err)
 
-    tokenCreator, err := NewJWTTokenCreator(utils.RandomString(32))
+    tokenCreator, err := NewJWTTokenCreator(utils.RandomString(32), Options{})
     require.NoError(t, err)
 
-    payload, err = tokenCreator.VerifyToken(token)
+    payload, err := tokenCreator.VerifyToken(token)
     require.Error(t, err)
     require.EqualError(t, err, ErrInvalidToken.Error())
     require.Nil(t, payload)
-}
\ No newline at end of file
+}
+
+func newTestClaims(duration time.Duration) jwtClaims {
+    now := time.Now()
+    return jwtClaims{
+        Role: utils.DepositorRole,
+        RegisteredClaims: jwt.RegisteredClaims{
+            ID:        uuid.New().String(),
+            Subject:   utils.RandomOwner(),
+            IssuedAt:  jwt.NewNumericDate(now),
+            NotBefore: jwt.NewNumericDate(now),
+            ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+        },
+    }
+}
+
+func TestJWTTokenCreatorClaims(t *testing.T) {
+    secretKey := utils.RandomString(32)
+    options := Options{Issuer: "gobank", Audience: "gobank-api"}
+
+    tokenCreator, err := NewJWTTokenCreator(secretKey, options)
+    require.NoError(t, err)
+
+    token, _, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+    require.NoError(t, err)
+
+    _, err = tokenCreator.VerifyToken(token)
+    require.NoError(t, err)
+
+    // Tokens issued by or for someone else are rejected, even when signed
+    // with the same secret.
+    for _, other := range []Options{
+        {Issuer: "other", Audience: options.Audience},
+        {Issuer: options.Issuer, Audience: "other"},
+        {},
+    } {
+        otherCreator, err := NewJWTTokenCreator(secretKey, other)
+        require.NoError(t, err)
+
+        otherToken, _, err := otherCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+        require.NoError(t, err)
+
+        payload, err := tokenCreator.VerifyToken(otherToken)
+        require.EqualError(t, err, ErrInvalidToken.Error())
+        require.Nil(t, payload)
+    }
+
+    notYetValid := newTestClaims(time.Hour)
+    notYetValid.Issuer, notYetValid.Audience = options.Issuer, jwt.ClaimStrings{options.Audience}
+    notYetValid.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Minute))
+
+    issuedInFuture := newTestClaims(time.Hour)
+    issuedInFuture.Issuer, issuedInFuture.Audience = options.Issuer, jwt.ClaimStrings{options.Audience}
+    issuedInFuture.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Minute))
+
+    neverExpires := newTestClaims(time.Hour)
+    neverExpires.Issuer, neverExpires.Audience = options.Issuer, jwt.ClaimStrings{options.Audience}
+    neverExpires.ExpiresAt = nil
+
+    for _, claims := range []jwtClaims{notYetValid, issuedInFuture, neverExpires} {
+        token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+        require.NoError(t, err)
+
+        payload, err := tokenCreator.VerifyToken(token)
+        require.EqualError(t, err, ErrInvalidToken.Error())
+        require.Nil(t, payload)
+    }
+}
+
+func TestJWTEdDSATokenCreator(t *testing.T) {
+    oldKeyring := newTestKeyring(t, "key-1")
+    keyring := newTestKeyring(t, "key-2", oldKeyring.PublicKeys()...)
+
+    tokenCreator, err := NewJWTEdDSATokenCreator(keyring, Options{})
+    require.NoError(t, err)
+
+    username := utils.RandomOwner()
+    token, created, err := tokenCreator.CreateToken(username, utils.BankerRole, time.Minute, Claims{})
+    require.NoError(t, err)
+
+    payload, err := tokenCreator.VerifyToken(token)
+    require.NoError(t, err)
+    require.Equal(t, created.ID, payload.ID)
+    require.Equal(t, username, payload.Username)
+    require.Equal(t, utils.BankerRole, payload.Role)
+    require.WithinDuration(t, created.IssuedAt, payload.IssuedAt, time.Second)
+    require.WithinDuration(t, created.ExpiredAt, payload.ExpiredAt, time.Second)
+
+    // Tokens signed with a previous key verify as long as the key is kept.
+    oldCreator, err := NewJWTEdDSATokenCreator(oldKeyring, Options{})
+    require.NoError(t, err)
+
+    oldToken, _, err := oldCreator.CreateToken(username, utils.DepositorRole, time.Minute, Claims{})
+    require.NoError(t, err)
+
+    _, err = tokenCreator.VerifyToken(oldToken)
+    require.NoError(t, err)
+
+    _, err = oldCreator.VerifyToken(token)
+    require.EqualError(t, err, ErrInvalidToken.Error())
+
+    // An HS256 token keyed with the public key must not pass as EdDSA.
+    publicKey := keyring.PublicKeys()[1].Key
+    jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims(time.Minute))
+    jwtToken.Header["kid"] = "key-2"
+    forged, err := jwtToken.SignedString([]byte(publicKey))
+    require.NoError(t, err)
+
+    payload, err = tokenCreator.VerifyToken(forged)
+    require.EqualError(t, err, ErrInvalidToken.Error())
+    require.Nil(t, payload)
+}
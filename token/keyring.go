@@ -0,0 +1,140 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PublicKey is a key tokens are verified with, as published to the services
+// that verify tokens themselves.
+type PublicKey struct {
+	ID  string
+	Key ed25519.PublicKey
+}
+
+// Keyring holds the Ed25519 key tokens are signed with and every key they
+// are verified with, each identified by a key ID that signed tokens carry.
+//
+// Keys are rotated by signing with a new key while the previous one stays a
+// verification key until the last token it signed has expired. Publishing
+// the new key as a verification key some time before signing with it gives
+// downstream services the chance to fetch it first.
+type Keyring struct {
+	signingKeyID     string
+	signingKey       ed25519.PrivateKey
+	verificationKeys map[string]ed25519.PublicKey
+}
+
+// NewKeyring creates a keyring signing with signingKey. Its public key is
+// a verification key too, along with verificationKeys.
+func NewKeyring(signingKeyID string, signingKey ed25519.PrivateKey, verificationKeys []PublicKey) (*Keyring, error) {
+	if signingKeyID == "" {
+		return nil, errors.New("signing key has no id")
+	}
+	if len(signingKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("signing key %q must be %d bytes", signingKeyID, ed25519.PrivateKeySize)
+	}
+
+	keyring := &Keyring{
+		signingKeyID: signingKeyID,
+		signingKey:   signingKey,
+		verificationKeys: map[string]ed25519.PublicKey{
+			signingKeyID: signingKey.Public().(ed25519.PublicKey),
+		},
+	}
+
+	for _, key := range verificationKeys {
+		if key.ID == "" {
+			return nil, errors.New("verification key has no id")
+		}
+		if len(key.Key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("verification key %q must be %d bytes", key.ID, ed25519.PublicKeySize)
+		}
+		if existing, ok := keyring.verificationKeys[key.ID]; ok && !existing.Equal(key.Key) {
+			return nil, fmt.Errorf("key id %q is used by two different keys", key.ID)
+		}
+		keyring.verificationKeys[key.ID] = key.Key
+	}
+
+	return keyring, nil
+}
+
+// ParseKeyring creates a keyring from its configuration, as generated by
+// GenerateSigningKey: signingKey is a key ID and a base64url Ed25519 seed
+// separated by a colon, verificationKeys a comma separated list of key IDs
+// and base64url public keys in the same format.
+func ParseKeyring(signingKey string, verificationKeys string) (*Keyring, error) {
+	id, seed, err := parseKey(signingKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key %q must be a %d byte seed", id, ed25519.SeedSize)
+	}
+
+	var publicKeys []PublicKey
+	for _, entry := range strings.Split(verificationKeys, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		id, key, err := parseKey(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification key: %w", err)
+		}
+		publicKeys = append(publicKeys, PublicKey{ID: id, Key: key})
+	}
+
+	return NewKeyring(id, ed25519.NewKeyFromSeed(seed), publicKeys)
+}
+
+// GenerateSigningKey returns a new signing key with the given ID, formatted
+// for ParseKeyring, together with its verification key.
+func GenerateSigningKey(id string) (signingKey string, verificationKey string, err error) {
+	if id == "" || strings.ContainsAny(id, ":,") {
+		return "", "", errors.New("key id must be non-empty and contain neither ':' nor ','")
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	signingKey = id + ":" + base64.RawURLEncoding.EncodeToString(privateKey.Seed())
+	verificationKey = id + ":" + base64.RawURLEncoding.EncodeToString(publicKey)
+	return signingKey, verificationKey, nil
+}
+
+// PublicKeys returns every verification key, ordered by ID.
+func (k *Keyring) PublicKeys() []PublicKey {
+	keys := make([]PublicKey, 0, len(k.verificationKeys))
+	for id, key := range k.verificationKeys {
+		keys = append(keys, PublicKey{ID: id, Key: key})
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+func (k *Keyring) verificationKey(id string) (ed25519.PublicKey, bool) {
+	key, ok := k.verificationKeys[id]
+	return key, ok
+}
+
+func parseKey(s string) (string, []byte, error) {
+	id, encoded, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok || id == "" {
+		return "", nil, errors.New("key must be formatted as id:base64url")
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("key %q is not base64url encoded", id)
+	}
+	return id, key, nil
+}
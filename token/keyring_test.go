@@ -0,0 +1,66 @@
+package token
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyring(t *testing.T) {
+	signingKey1, verificationKey1, err := GenerateSigningKey("key-1")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(signingKey1, "key-1:"))
+
+	signingKey2, verificationKey2, err := GenerateSigningKey("key-2")
+	require.NoError(t, err)
+
+	keyring, err := ParseKeyring(signingKey2, verificationKey1)
+	require.NoError(t, err)
+	require.Equal(t, "key-2", keyring.signingKeyID)
+
+	keys := keyring.PublicKeys()
+	require.Len(t, keys, 2)
+	require.Equal(t, "key-1", keys[0].ID)
+	require.Equal(t, "key-2", keys[1].ID)
+
+	// Listing the signing key among the verification keys is harmless.
+	keyring, err = ParseKeyring(signingKey2, verificationKey1+", "+verificationKey2)
+	require.NoError(t, err)
+	require.Len(t, keyring.PublicKeys(), 2)
+
+	keyring, err = ParseKeyring(signingKey1, "")
+	require.NoError(t, err)
+	require.Len(t, keyring.PublicKeys(), 1)
+}
+
+func TestParseInvalidKeyring(t *testing.T) {
+	signingKey1, _, err := GenerateSigningKey("key-1")
+	require.NoError(t, err)
+
+	_, otherVerificationKey1, err := GenerateSigningKey("key-1")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name             string
+		signingKey       string
+		verificationKeys string
+	}{
+		{"NoSigningKey", "", ""},
+		{"NoKeyID", ":" + strings.SplitN(signingKey1, ":", 2)[1], ""},
+		{"NotBase64", "key-1:not base64!", ""},
+		{"ShortSeed", "key-1:AAAA", ""},
+		{"ShortVerificationKey", signingKey1, "key-2:AAAA"},
+		{"ConflictingKeyID", signingKey1, otherVerificationKey1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseKeyring(tc.signingKey, tc.verificationKeys)
+			require.Error(t, err)
+		})
+	}
+
+	_, _, err = GenerateSigningKey("key:1")
+	require.Error(t, err)
+}
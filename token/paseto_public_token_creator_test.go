@@ -0,0 +1,140 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func newTestKeyring(t *testing.T, id string, verificationKeys ...PublicKey) *Keyring {
+	_, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(id, privateKey, verificationKeys)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestPasetoPublicTokenCreator(t *testing.T) {
+	tokenCreator, err := NewPasetoPublicTokenCreator(newTestKeyring(t, "key-1"), Options{})
+	require.NoError(t, err)
+
+	username := utils.RandomOwner()
+	role := utils.DepositorRole
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiresAt := issuedAt.Add(duration)
+
+	token, payload, err := tokenCreator.CreateToken(username, role, duration, Claims{})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+	require.NotEmpty(t, payload)
+
+	payload, err = tokenCreator.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotNil(t, payload)
+
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.NotZero(t, payload.ID)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiresAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredPasetoPublicTokenCreator(t *testing.T) {
+	tokenCreator, err := NewPasetoPublicTokenCreator(newTestKeyring(t, "key-1"), Options{})
+	require.NoError(t, err)
+
+	token, _, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, -time.Minute, Claims{})
+	require.NoError(t, err)
+
+	payload, err := tokenCreator.VerifyToken(token)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicTokenCreatorRotation(t *testing.T) {
+	oldKeyring := newTestKeyring(t, "key-1")
+	oldCreator, err := NewPasetoPublicTokenCreator(oldKeyring, Options{})
+	require.NoError(t, err)
+
+	oldToken, _, err := oldCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+	require.NoError(t, err)
+
+	// After the rotation, tokens signed with the old key stay valid as long
+	// as it is kept as a verification key.
+	newCreator, err := NewPasetoPublicTokenCreator(newTestKeyring(t, "key-2", oldKeyring.PublicKeys()...), Options{})
+	require.NoError(t, err)
+
+	_, err = newCreator.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, _, err := newCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+	require.NoError(t, err)
+
+	_, err = oldCreator.VerifyToken(newToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// Once the old key is dropped its tokens are rejected.
+	droppedCreator, err := NewPasetoPublicTokenCreator(newTestKeyring(t, "key-3"), Options{})
+	require.NoError(t, err)
+
+	_, err = droppedCreator.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestInvalidPasetoPublicToken(t *testing.T) {
+	keyring := newTestKeyring(t, "key-1")
+	tokenCreator, err := NewPasetoPublicTokenCreator(keyring, Options{})
+	require.NoError(t, err)
+
+	token, _, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+	require.NoError(t, err)
+
+	body, footer, found := strings.Cut(strings.TrimPrefix(token, pasetoV4PublicHeader), ".")
+	require.True(t, found)
+
+	// An attacker holding another key cannot claim to be signing with ours.
+	otherCreator, err := NewPasetoPublicTokenCreator(newTestKeyring(t, "key-1"), Options{})
+	require.NoError(t, err)
+	forged, _, err := otherCreator.CreateToken(utils.RandomOwner(), utils.BankerRole, time.Minute, Claims{})
+	require.NoError(t, err)
+
+	tampered := []byte(body)
+	tampered[10] ^= 1
+
+	for _, invalid := range []string{
+		"",
+		"v2.local." + body + "." + footer,
+		pasetoV4PublicHeader + string(tampered) + "." + footer,
+		pasetoV4PublicHeader + body,
+		pasetoV4PublicHeader + body + ".e30",
+		forged,
+	} {
+		payload, err := tokenCreator.VerifyToken(invalid)
+		require.EqualError(t, err, ErrInvalidToken.Error())
+		require.Nil(t, payload)
+	}
+}
+
+// TestPasetoV4PublicVector checks the signature against test vector 4-S-1
+// of the PASETO specification.
+func TestPasetoV4PublicVector(t *testing.T) {
+	secretKey, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+
+	message := []byte(`{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`)
+	signature := ed25519.Sign(secretKey, pae([]byte(pasetoV4PublicHeader), message, nil, nil))
+
+	expected := "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9" +
+		"bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"
+	require.Equal(t, expected, pasetoV4PublicHeader+base64.RawURLEncoding.EncodeToString(append(message, signature...)))
+}
@@ -11,9 +11,10 @@ import (
 type PasetoTokenCreator struct {
     paseto *paseto.V2
     symmetricKey []byte
+    options Options
 }
 
-func NewPasetoTokenCreator(symmetricKey string) (TokenCreator, error) {
+func NewPasetoTokenCreator(symmetricKey string, options Options) (TokenCreator, error) {
     if len(symmetricKey) != chacha20poly1305.KeySize {
         return nil, fmt.Errorf("invalid key size, must be %d characters", chacha20poly1305.KeySize)
     }
@@ -21,16 +22,18 @@ func NewPasetoTokenCreator(symmetricKey string) (TokenCreator, error) {
     pasetoTokenCreator := &PasetoTokenCreator{
         paseto: paseto.NewV2(),
         symmetricKey: []byte(symmetricKey),
+        options: options,
     }
     return pasetoTokenCreator, nil
 }
 
-func (pasetoT *PasetoTokenCreator) CreateToken(username string, duration time.Duration) (string, error) {
-    payload, err := NewPayload(username, duration)
+func (pasetoT *PasetoTokenCreator) CreateToken(username string, role string, duration time.Duration, claims Claims) (string, *Payload, error) {
+    payload, err := NewPayload(username, role, duration, claims, pasetoT.options)
     if err != nil {
-        return "", err
+        return "", nil, err
     }
-    return pasetoT.paseto.Encrypt(pasetoT.symmetricKey, payload, nil)
+    token, err := pasetoT.paseto.Encrypt(pasetoT.symmetricKey, payload, nil)
+    return token, payload, err
 }
 
 func (pasetoT *PasetoTokenCreator) VerifyToken(token string) (*Payload, error){
@@ -40,7 +43,7 @@ func (pasetoT *PasetoTokenCreator) VerifyToken(token string) (*Payload, error){
         return nil, ErrInvalidToken
     }
     
-    if err := payload.Valid(); err != nil {
+    if err := payload.Valid(pasetoT.options); err != nil {
         return nil, err
     }
 
@@ -9,38 +9,42 @@ import (
 )
 
 func TestPasetoTokenCreator(t *testing.T) {
-    tokenCreator, err := NewPasetoTokenCreator(utils.RandomString(32))
+    tokenCreator, err := NewPasetoTokenCreator(utils.RandomString(32), Options{})
     require.NoError(t, err)
 
     username := utils.RandomOwner()
+    role := utils.DepositorRole
     duration := time.Minute
 
     issuedAt := time.Now()
     expiresAt := issuedAt.Add(duration)
 
-    token, err := tokenCreator.CreateToken(username, duration)
+    token, payload, err := tokenCreator.CreateToken(username, role, duration, Claims{})
     require.NoError(t, err)
     require.NotEmpty(t, token)
+    require.NotEmpty(t, payload)
 
-    payload, err := tokenCreator.VerifyToken(token)
+    payload, err = tokenCreator.VerifyToken(token)
     require.NoError(t, err)
     require.NotNil(t, payload)
 
     require.Equal(t, username, payload.Username)
+    require.Equal(t, role, payload.Role)
     require.NotZero(t, payload.ID)
     require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
     require.WithinDuration(t, expiresAt, payload.ExpiredAt, time.Second)
 }
 
 func TestExpiredPasetoTokenCreator(t *testing.T) {
-    tokenCreator, err := NewPasetoTokenCreator(utils.RandomString(32))
+    tokenCreator, err := NewPasetoTokenCreator(utils.RandomString(32), Options{})
     require.NoError(t, err)
 
-    token, err := tokenCreator.CreateToken(utils.RandomOwner(), -time.Minute)
+    token, payload, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, -time.Minute, Claims{})
     require.NoError(t, err)
     require.NotEmpty(t, token)
+    require.NotEmpty(t, payload)
 
-    payload, err := tokenCreator.VerifyToken(token)
+    payload, err = tokenCreator.VerifyToken(token)
     require.Error(t, err)
     require.EqualError(t, err, ErrExpiredToken.Error())
     require.Nil(t, payload)
@@ -12,33 +12,100 @@ var (
     ErrInvalidToken = errors.New("token is invalid")
 )
 
+// The types of token. Only access tokens authenticate requests; refresh
+// tokens are only good for getting new access tokens.
+const (
+    TypeAccess  = "access"
+    TypeRefresh = "refresh"
+)
+
+// Claims are the claims of a new token beyond who it is for.
+type Claims struct {
+    // Type is what the token is for, TypeAccess unless given.
+    Type string
+    // Scopes restrict what the token may be used for. A token without
+    // scopes may be used for anything its role allows.
+    Scopes []string
+    // SessionID is the login session an access token was issued for.
+    SessionID uuid.UUID
+}
+
 type Payload struct {
     ID  uuid.UUID `json:"id"`
     Username string `json:"username"`
+    Role string `json:"role"`
+    Type string `json:"type"`
+    Issuer string `json:"issuer,omitempty"`
+    Audience string `json:"audience,omitempty"`
+    Scopes []string `json:"scopes,omitempty"`
+    SessionID uuid.UUID `json:"session_id"`
     IssuedAt time.Time `json:"issued_at"`
+    NotBefore time.Time `json:"not_before"`
     ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, role string, duration time.Duration, claims Claims, options Options) (*Payload, error) {
     tokenID, err := uuid.NewRandom()
     if err != nil {
         return nil, err
     }
 
+    tokenType := claims.Type
+    if tokenType == "" {
+        tokenType = TypeAccess
+    }
+
+    now := time.Now()
     payload := &Payload{
         ID:        tokenID,
         Username:  username,
-        IssuedAt:  time.Now(),
-        ExpiredAt: time.Now().Add(duration),
+        Role:      role,
+        Type:      tokenType,
+        Issuer:    options.Issuer,
+        Audience:  options.Audience,
+        Scopes:    claims.Scopes,
+        SessionID: claims.SessionID,
+        IssuedAt:  now,
+        NotBefore: now,
+        ExpiredAt: now.Add(duration),
     }
 
     return payload, nil
 }
 
-func (p *Payload) Valid() error {
-    if time.Now().After(p.ExpiredAt) {
+// Valid checks the time claims of the payload, and that it was issued by
+// and for whom options expect.
+func (p *Payload) Valid(options Options) error {
+    now := time.Now()
+    if now.After(p.ExpiredAt) {
         return ErrExpiredToken
     }
 
+    if p.NotBefore.After(now.Add(leeway)) {
+        return ErrInvalidToken
+    }
+
+    if options.Issuer != "" && p.Issuer != options.Issuer {
+        return ErrInvalidToken
+    }
+
+    if options.Audience != "" && p.Audience != options.Audience {
+        return ErrInvalidToken
+    }
+
     return nil
-}
\ No newline at end of file
+}
+
+// HasScope reports whether the token may be used for scope.
+func (p *Payload) HasScope(scope string) bool {
+    if len(p.Scopes) == 0 {
+        return true
+    }
+
+    for _, s := range p.Scopes {
+        if s == scope {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,175 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/wenealves10/gobank/utils"
+)
+
+func TestPayloadValid(t *testing.T) {
+	options := Options{Issuer: "gobank", Audience: "gobank-api"}
+
+	testCases := []struct {
+		name    string
+		update  func(payload *Payload)
+		options Options
+		err     error
+	}{
+		{
+			name:    "OK",
+			update:  func(payload *Payload) {},
+			options: options,
+		},
+		{
+			name:    "NoExpectations",
+			update:  func(payload *Payload) { payload.Issuer, payload.Audience = "", "" },
+			options: Options{},
+		},
+		{
+			name:    "Expired",
+			update:  func(payload *Payload) { payload.ExpiredAt = time.Now().Add(-time.Second) },
+			options: options,
+			err:     ErrExpiredToken,
+		},
+		{
+			name:    "NotYetValid",
+			update:  func(payload *Payload) { payload.NotBefore = time.Now().Add(time.Minute) },
+			options: options,
+			err:     ErrInvalidToken,
+		},
+		{
+			name:    "NotBeforeWithinLeeway",
+			update:  func(payload *Payload) { payload.NotBefore = time.Now().Add(leeway / 2) },
+			options: options,
+		},
+		{
+			name:    "OtherIssuer",
+			update:  func(payload *Payload) { payload.Issuer = "other" },
+			options: options,
+			err:     ErrInvalidToken,
+		},
+		{
+			name:    "OtherAudience",
+			update:  func(payload *Payload) { payload.Audience = "other" },
+			options: options,
+			err:     ErrInvalidToken,
+		},
+		{
+			name:    "MissingAudience",
+			update:  func(payload *Payload) { payload.Audience = "" },
+			options: options,
+			err:     ErrInvalidToken,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := NewPayload(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{}, options)
+			require.NoError(t, err)
+
+			tc.update(payload)
+
+			err = payload.Valid(tc.options)
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.err.Error())
+			}
+		})
+	}
+}
+
+func TestPayloadHasScope(t *testing.T) {
+	unscoped := &Payload{}
+	require.True(t, unscoped.HasScope("accounts:read"))
+
+	scoped := &Payload{Scopes: []string{"accounts:read", "transfers:read"}}
+	require.True(t, scoped.HasScope("accounts:read"))
+	require.True(t, scoped.HasScope("transfers:read"))
+	require.False(t, scoped.HasScope("transfers:write"))
+}
+
+// TestTokenCreatorsClaims checks that every backend carries the claims of
+// its tokens and validates them the same way.
+func TestTokenCreatorsClaims(t *testing.T) {
+	symmetricKey := utils.RandomString(32)
+	keyring := newTestKeyring(t, "key-1")
+
+	backends := map[string]func(options Options) (TokenCreator, error){
+		"paseto-local": func(options Options) (TokenCreator, error) {
+			return NewPasetoTokenCreator(symmetricKey, options)
+		},
+		"paseto-public": func(options Options) (TokenCreator, error) {
+			return NewPasetoPublicTokenCreator(keyring, options)
+		},
+		"jwt-hs256": func(options Options) (TokenCreator, error) {
+			return NewJWTTokenCreator(symmetricKey, options)
+		},
+		"jwt-eddsa": func(options Options) (TokenCreator, error) {
+			return NewJWTEdDSATokenCreator(keyring, options)
+		},
+	}
+
+	options := Options{Issuer: "gobank", Audience: "gobank-api"}
+
+	for name, newCreator := range backends {
+		newCreator := newCreator
+
+		t.Run(name, func(t *testing.T) {
+			tokenCreator, err := newCreator(options)
+			require.NoError(t, err)
+
+			claims := Claims{
+				Type:      TypeRefresh,
+				Scopes:    []string{"accounts:read", "transfers:read"},
+				SessionID: uuid.New(),
+			}
+
+			token, created, err := tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, claims)
+			require.NoError(t, err)
+
+			payload, err := tokenCreator.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, created.ID, payload.ID)
+			require.Equal(t, options.Issuer, payload.Issuer)
+			require.Equal(t, options.Audience, payload.Audience)
+			require.Equal(t, claims.Type, payload.Type)
+			require.Equal(t, claims.Scopes, payload.Scopes)
+			require.Equal(t, claims.SessionID, payload.SessionID)
+			require.WithinDuration(t, created.NotBefore, payload.NotBefore, time.Second)
+
+			// Unscoped access tokens outside a session stay so.
+			token, _, err = tokenCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+			require.NoError(t, err)
+
+			payload, err = tokenCreator.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, TypeAccess, payload.Type)
+			require.Empty(t, payload.Scopes)
+			require.Equal(t, uuid.Nil, payload.SessionID)
+
+			// Tokens issued by or for someone else are rejected, even when
+			// signed with the same key.
+			for _, other := range []Options{
+				{Issuer: "other", Audience: options.Audience},
+				{Issuer: options.Issuer, Audience: "other"},
+				{},
+			} {
+				otherCreator, err := newCreator(other)
+				require.NoError(t, err)
+
+				otherToken, _, err := otherCreator.CreateToken(utils.RandomOwner(), utils.DepositorRole, time.Minute, Claims{})
+				require.NoError(t, err)
+
+				payload, err := tokenCreator.VerifyToken(otherToken)
+				require.EqualError(t, err, ErrInvalidToken.Error())
+				require.Nil(t, payload)
+			}
+		})
+	}
+}
@@ -4,7 +4,19 @@ import (
 	"time"
 )
 
+// leeway absorbs the clock skew between the servers creating and verifying
+// tokens when their time claims are checked.
+const leeway = 5 * time.Second
+
+// Options are the claims a token creator stamps on the tokens it creates
+// and requires of the tokens it verifies. Empty options are neither set
+// nor checked.
+type Options struct {
+	Issuer   string
+	Audience string
+}
+
 type TokenCreator interface {
-	CreateToken(username string, duration time.Duration) (string, error)
+	CreateToken(username string, role string, duration time.Duration, claims Claims) (string, *Payload, error)
 	VerifyToken(token string) (*Payload, error)
 }
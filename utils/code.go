@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewSecretCode returns a random single-use code, such as the ones sent by
+// email to verify an address or reset a password.
+func NewSecretCode() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// NewRecoveryCode returns a random two-factor authentication recovery code
+// of ten lowercase letters and digits, short enough to write down.
+func NewRecoveryCode() (string, error) {
+	return randomCode(10)
+}
+
+// NewAPIKeyPrefix returns the random public part of an API key, which the
+// key is looked up by.
+func NewAPIKeyPrefix() (string, error) {
+	return randomCode(12)
+}
+
+// randomCode returns n random lowercase letters and digits.
+func randomCode(n int) (string, error) {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = alphabet[int(b)%len(alphabet)]
+	}
+	return string(buf), nil
+}
+
+// HashSecretCode returns the hash a secret code is stored and looked up by,
+// so that a leaked table does not reveal usable codes.
+func HashSecretCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
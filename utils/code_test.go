@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretCode(t *testing.T) {
+	code1, err := NewSecretCode()
+	require.NoError(t, err)
+	require.Len(t, code1, 32)
+
+	code2, err := NewSecretCode()
+	require.NoError(t, err)
+	require.NotEqual(t, code1, code2)
+
+	hash := HashSecretCode(code1)
+	require.Len(t, hash, 64)
+	require.Equal(t, hash, HashSecretCode(code1))
+	require.NotEqual(t, hash, HashSecretCode(code2))
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code, err := NewRecoveryCode()
+	require.NoError(t, err)
+	require.Regexp(t, "^[a-z2-7]{10}$", code)
+
+	other, err := NewRecoveryCode()
+	require.NoError(t, err)
+	require.NotEqual(t, code, other)
+}
+
+func TestAPIKeyPrefix(t *testing.T) {
+	prefix, err := NewAPIKeyPrefix()
+	require.NoError(t, err)
+	require.Regexp(t, "^[a-z2-7]{12}$", prefix)
+
+	other, err := NewAPIKeyPrefix()
+	require.NoError(t, err)
+	require.NotEqual(t, prefix, other)
+}
@@ -7,11 +7,66 @@ import (
 )
 
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenPassetoKey     string        `mapstructure:"TOKEN_PASETO_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBDriver             string        `mapstructure:"DB_DRIVER"`
+	DBSource             string        `mapstructure:"DB_SOURCE"`
+	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
+	TokenPassetoKey      string        `mapstructure:"TOKEN_PASETO_KEY"`
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	// TokenBackend is the format tokens are created in: paseto-local (the
+	// default), paseto-public, jwt-hs256 or jwt-eddsa. Tokens in the format
+	// of TokenPreviousBackend are accepted too, so that switching formats
+	// does not log anyone out until they expire.
+	TokenBackend         string `mapstructure:"TOKEN_BACKEND"`
+	TokenPreviousBackend string `mapstructure:"TOKEN_PREVIOUS_BACKEND"`
+	// TokenJWTSecret is the HS256 secret of jwt-hs256 tokens.
+	TokenJWTSecret string `mapstructure:"TOKEN_JWT_SECRET"`
+	// TokenSigningKey is the Ed25519 key paseto-public and jwt-eddsa tokens
+	// are signed with, e.g. "2023-06:<base64url seed>". TokenVerificationKeys
+	// lists the other keys tokens are accepted from while keys are rotated.
+	// Both are generated with "go run main.go token genkey <id>".
+	TokenSigningKey       string `mapstructure:"TOKEN_SIGNING_KEY"`
+	TokenVerificationKeys string `mapstructure:"TOKEN_VERIFICATION_KEYS"`
+	// TokenIssuer and TokenAudience are stamped on tokens of every backend,
+	// and tokens naming another issuer or audience are rejected.
+	TokenIssuer   string `mapstructure:"TOKEN_ISSUER"`
+	TokenAudience string `mapstructure:"TOKEN_AUDIENCE"`
+	// TokenRevocationCacheTTL bounds how long a revocation made by another
+	// replica may go unnoticed.
+	TokenRevocationCacheTTL time.Duration `mapstructure:"TOKEN_REVOCATION_CACHE_TTL"`
+	// ExchangeRatesFile is a JSON file of exchange rates. Without it only
+	// same-currency transfers are possible.
+	ExchangeRatesFile string `mapstructure:"EXCHANGE_RATES_FILE"`
+	// Currencies are the supported currencies, e.g. "USD:2:$,EUR:2:€",
+	// instead of those of the currencies table. They are saved to it.
+	Currencies string `mapstructure:"CURRENCIES"`
+	// ReconcileInterval is how often the server compares account balances
+	// with their entries, only reporting drift. Zero disables the scheduled
+	// reconciliation.
+	ReconcileInterval time.Duration `mapstructure:"RECONCILE_INTERVAL"`
+	// SchedulerInterval is how often the server runs due scheduled
+	// transfers. Zero disables the scheduler on this replica.
+	SchedulerInterval time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	// LoginMaxFailures is how many failed logins a username may have within
+	// LoginLockoutDuration before further attempts are refused until the
+	// oldest of them is that old. LoginMaxFailuresPerIP is the same limit
+	// for a client IP across all usernames.
+	LoginMaxFailures      int           `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginMaxFailuresPerIP int           `mapstructure:"LOGIN_MAX_FAILURES_PER_IP"`
+	LoginLockoutDuration  time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	// LoginBackoff is how long a username must wait after its first failed
+	// login. The wait doubles with every further failure.
+	LoginBackoff time.Duration `mapstructure:"LOGIN_BACKOFF"`
+	// SMTPHost is the server emails are sent through. Without it emails are
+	// appended to MailFile, or kept in memory and never delivered when that
+	// is empty too.
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	// MailFrom is the sender address of every email.
+	MailFrom string `mapstructure:"MAIL_FROM"`
+	MailFile string `mapstructure:"MAIL_FILE"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -1,15 +1,149 @@
 package utils
 
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
 const (
 	USD = "USD"
 	EUR = "EUR"
 	CAD = "CAD"
 )
 
+// Currency describes an ISO 4217 currency. Amounts are stored in minor
+// units, so 1050 with an exponent of 2 is 10.50.
+type Currency struct {
+	Code     string `json:"code"`
+	Exponent int    `json:"exponent"`
+	Symbol   string `json:"symbol"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// FormatAmount renders an amount in minor units as a decimal string.
+func (c Currency) FormatAmount(amount int64) string {
+	if c.Exponent <= 0 {
+		return strconv.FormatInt(amount, 10)
+	}
+
+	sign := ""
+	digits := strconv.FormatInt(amount, 10)
+	if amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	if len(digits) <= c.Exponent {
+		digits = strings.Repeat("0", c.Exponent-len(digits)+1) + digits
+	}
+
+	point := len(digits) - c.Exponent
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// DefaultCurrencies are used until a registry is loaded from config or the
+// database.
+var DefaultCurrencies = []Currency{
+	{Code: USD, Exponent: 2, Symbol: "$", Enabled: true},
+	{Code: EUR, Exponent: 2, Symbol: "€", Enabled: true},
+	{Code: CAD, Exponent: 2, Symbol: "CA$", Enabled: true},
+}
+
+// CurrencyRegistry is the set of currencies the bank knows about. It is
+// safe for concurrent use.
+type CurrencyRegistry struct {
+	mu         sync.RWMutex
+	currencies map[string]Currency
+}
+
+func NewCurrencyRegistry(currencies []Currency) *CurrencyRegistry {
+	registry := &CurrencyRegistry{}
+	registry.Replace(currencies)
+	return registry
+}
+
+// Currencies is the registry used by IsValidCurrency and the API.
+var Currencies = NewCurrencyRegistry(DefaultCurrencies)
+
+// Replace swaps the contents of the registry.
+func (r *CurrencyRegistry) Replace(currencies []Currency) {
+	byCode := make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+	}
+
+	r.mu.Lock()
+	r.currencies = byCode
+	r.mu.Unlock()
+}
+
+func (r *CurrencyRegistry) Get(code string) (Currency, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	currency, ok := r.currencies[code]
+	return currency, ok
+}
+
+// List returns every currency, enabled or not, ordered by code.
+func (r *CurrencyRegistry) List() []Currency {
+	r.mu.RLock()
+	currencies := make([]Currency, 0, len(r.currencies))
+	for _, currency := range r.currencies {
+		currencies = append(currencies, currency)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Code < currencies[j].Code
+	})
+	return currencies
+}
+
+// IsEnabled reports whether new accounts and transfers may use the currency.
+func (r *CurrencyRegistry) IsEnabled(code string) bool {
+	currency, ok := r.Get(code)
+	return ok && currency.Enabled
+}
+
 func IsValidCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, CAD:
-		return true
+	return Currencies.IsEnabled(currency)
+}
+
+// ParseCurrencies reads a comma separated list of CODE:EXPONENT:SYMBOL
+// entries, e.g. "USD:2:$,JPY:0:¥". Every parsed currency is enabled.
+func ParseCurrencies(spec string) ([]Currency, error) {
+	var currencies []Currency
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.SplitN(entry, ":", 3)
+		if len(fields) != 3 || len(fields[0]) != 3 {
+			return nil, fmt.Errorf("invalid currency %q, must look like USD:2:$", entry)
+		}
+
+		exponent, err := strconv.Atoi(fields[1])
+		if err != nil || exponent < 0 {
+			return nil, fmt.Errorf("invalid exponent for currency %s: %q", fields[0], fields[1])
+		}
+
+		currencies = append(currencies, Currency{
+			Code:     strings.ToUpper(fields[0]),
+			Exponent: exponent,
+			Symbol:   fields[2],
+			Enabled:  true,
+		})
 	}
-	return false
+
+	if len(currencies) == 0 {
+		return nil, fmt.Errorf("no currencies in %q", spec)
+	}
+
+	return currencies, nil
 }
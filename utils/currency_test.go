@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyFormatAmount(t *testing.T) {
+	usd := Currency{Code: USD, Exponent: 2}
+	jpy := Currency{Code: "JPY", Exponent: 0}
+	bhd := Currency{Code: "BHD", Exponent: 3}
+
+	testCases := []struct {
+		currency Currency
+		amount   int64
+		expected string
+	}{
+		{currency: usd, amount: 1050, expected: "10.50"},
+		{currency: usd, amount: 5, expected: "0.05"},
+		{currency: usd, amount: 0, expected: "0.00"},
+		{currency: usd, amount: -1050, expected: "-10.50"},
+		{currency: usd, amount: -5, expected: "-0.05"},
+		{currency: jpy, amount: 1851, expected: "1851"},
+		{currency: bhd, amount: 1234567, expected: "1234.567"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, tc.currency.FormatAmount(tc.amount))
+	}
+}
+
+func TestCurrencyRegistry(t *testing.T) {
+	registry := NewCurrencyRegistry([]Currency{
+		{Code: USD, Exponent: 2, Symbol: "$", Enabled: true},
+		{Code: "JPY", Exponent: 0, Symbol: "¥", Enabled: false},
+	})
+
+	currency, ok := registry.Get(USD)
+	require.True(t, ok)
+	require.Equal(t, "$", currency.Symbol)
+
+	require.True(t, registry.IsEnabled(USD))
+	require.False(t, registry.IsEnabled("JPY"))
+	require.False(t, registry.IsEnabled(EUR))
+
+	currencies := registry.List()
+	require.Len(t, currencies, 2)
+	require.Equal(t, "JPY", currencies[0].Code)
+	require.Equal(t, USD, currencies[1].Code)
+
+	registry.Replace([]Currency{{Code: EUR, Exponent: 2, Enabled: true}})
+	require.True(t, registry.IsEnabled(EUR))
+	require.False(t, registry.IsEnabled(USD))
+}
+
+func TestParseCurrencies(t *testing.T) {
+	currencies, err := ParseCurrencies("USD:2:$, jpy:0:¥")
+	require.NoError(t, err)
+	require.Equal(t, []Currency{
+		{Code: USD, Exponent: 2, Symbol: "$", Enabled: true},
+		{Code: "JPY", Exponent: 0, Symbol: "¥", Enabled: true},
+	}, currencies)
+
+	for _, spec := range []string{"", "USD", "USD:x:$", "USD:-1:$", "DOLLAR:2:$"} {
+		_, err := ParseCurrencies(spec)
+		require.Error(t, err, spec)
+	}
+}
@@ -39,9 +39,14 @@ func RandomMoney() int64 {
 	return RandomInt(0, 1000)
 }
 
-// RandomCurrency generates a random currency code
+// RandomCurrency generates a random code of an enabled currency
 func RandomCurrency() string {
-	currencies := []string{USD, EUR, CAD}
+	var currencies []string
+	for _, currency := range Currencies.List() {
+		if currency.Enabled {
+			currencies = append(currencies, currency.Code)
+		}
+	}
 	n := len(currencies)
 	return currencies[rand.Intn(n)]
 }